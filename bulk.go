@@ -0,0 +1,212 @@
+package onetimesecret
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DefaultBulkConcurrency is the number of secrets Bulk creates at once unless BulkOptions say otherwise
+const DefaultBulkConcurrency = 4
+
+// BulkRequest is one secret to create with Bulk
+//
+//  Attributes
+//
+//    ID: identifies the request in its BulkResult and, when resuming, across runs. It defaults to the position of the
+//        request, counting from 1, and must be unique.
+//    Secret: the secret value. A random secret is generated by the service when it is empty.
+//    Passphrase: a string that the recipient must know to view the secret, if any.
+//    GeneratePassphrase: whether to use a random passphrase, returned in the BulkResult, instead of Passphrase.
+//    TTL: the maximum amount of time that the secret should survive, the service's default if zero.
+//    Recipient: the email addresses to send the share link to, if any.
+type BulkRequest struct {
+	ID                 string
+	Secret             string
+	Passphrase         string
+	GeneratePassphrase bool
+	TTL                time.Duration
+	Recipient          []string
+}
+
+// BulkResult is the outcome of one BulkRequest
+//
+//  Attributes
+//
+//    ID: the ID of the BulkRequest.
+//    Metadata: the metadata of the created secret, nil if it was not created.
+//    Value: the secret value the service generated, for a BulkRequest without a Secret.
+//    Passphrase: the generated passphrase, for a BulkRequest with GeneratePassphrase.
+//    Err: why the secret was not created, nil if it was.
+//    Resumed: whether the result was taken from BulkOptions.Previous rather than created by this call.
+type BulkResult struct {
+	ID         string
+	Metadata   *Metadata
+	Value      string
+	Passphrase string
+	Err        error
+	Resumed    bool
+}
+
+// BulkOptions adjust how Bulk creates secrets
+//
+//  Attributes
+//
+//    Concurrency: the number of secrets created at once, DefaultBulkConcurrency if zero.
+//    Previous: the results of an earlier, partially failed call. Requests whose ID has a result there without an
+//              error are not created again, and that result is returned in their place.
+//    OnResult: called with every result as soon as it is known, one at a time, e.g. to save progress so that a
+//              later call can resume from it.
+type BulkOptions struct {
+	Concurrency int
+	Previous    []BulkResult
+	OnResult    func(BulkResult)
+}
+
+// Bulk will create many secrets concurrently, with a bounded number of requests in flight
+//
+// A failed request does not stop the others: its error is reported in its BulkResult. When ctx is done, the requests
+// that were not sent yet fail with ctx.Err(). Like CreateSecret and GenerateSecret, the requests are never retried;
+// pass the results back as BulkOptions.Previous to retry the failed ones without duplicating the others.
+//
+// Variables:
+//     ctx (context.Context):   The context that controls cancellation and deadlines of the requests
+//     requests ([]BulkRequest): The secrets to create
+//     opts (*BulkOptions):     A pointer to the BulkOptions, nil for the defaults
+//
+// Returns:
+//     ([]BulkResult): The result of every request, in the order of requests, nil if an error occurred
+//     (error):        An error if the IDs of the requests are not unique, nil otherwise
+func (C *Client) Bulk(ctx context.Context, requests []BulkRequest, opts *BulkOptions) ([]BulkResult, error) {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	ids := make([]string, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for i := range requests {
+		ids[i] = requests[i].ID
+		if ids[i] == "" {
+			ids[i] = strconv.Itoa(i + 1)
+		}
+		if seen[ids[i]] {
+			return nil, fmt.Errorf("bulk request ID %q is not unique", ids[i])
+		}
+		seen[ids[i]] = true
+	}
+
+	previous := make(map[string]BulkResult, len(opts.Previous))
+	for _, r := range opts.Previous {
+		if r.Err == nil && r.Metadata != nil {
+			previous[r.ID] = r
+		}
+	}
+
+	report := func(r BulkResult) {
+		if opts.OnResult != nil {
+			opts.OnResult(r)
+		}
+	}
+
+	results := make([]BulkResult, len(requests))
+	var pending []int
+	for i := range requests {
+		if r, ok := previous[ids[i]]; ok {
+			r.Resumed = true
+			results[i] = r
+			report(r)
+			continue
+		}
+		pending = append(pending, i)
+	}
+	if concurrency > len(pending) {
+		concurrency = len(pending)
+	}
+
+	type done struct {
+		i int
+		r BulkResult
+	}
+	jobs := make(chan int)
+	out := make(chan done)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			for i := range jobs {
+				out <- done{i, C.bulkCreate(ctx, ids[i], &requests[i])}
+			}
+		}()
+	}
+	go func() {
+		for _, i := range pending {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	for range pending {
+		d := <-out
+		results[d.i] = d.r
+		report(d.r)
+	}
+	return results, nil
+}
+
+// bulkCreate creates the secret of a single BulkRequest
+func (C *Client) bulkCreate(ctx context.Context, id string, request *BulkRequest) BulkResult {
+	result := BulkResult{ID: id}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	passphrase := request.Passphrase
+	if request.GeneratePassphrase {
+		b := make([]byte, 18)
+		if _, err := rand.Read(b); err != nil {
+			result.Err = fmt.Errorf("generating a passphrase: %v", err)
+			return result
+		}
+		passphrase = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	if request.Secret == "" {
+		resp, err := C.GenerateSecretWithContext(ctx, &GenerateSecretRequest{
+			Passphrase: passphrase,
+			TTL:        request.TTL,
+			Recipient:  request.Recipient,
+		})
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Metadata = &resp.Metadata
+		result.Value = resp.Value
+		if request.GeneratePassphrase {
+			result.Passphrase = passphrase
+		}
+		return result
+	}
+
+	resp, err := C.CreateSecretWithContext(ctx, &CreateSecretRequest{
+		Secret:     request.Secret,
+		Passphrase: passphrase,
+		TTL:        request.TTL,
+		Recipient:  request.Recipient,
+	})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Metadata = &resp.Metadata
+	if request.GeneratePassphrase {
+		result.Passphrase = passphrase
+	}
+	return result
+}
@@ -0,0 +1,276 @@
+// Package cassette records the HTTP interactions of a onetimesecret Client to a file and replays them, so that tests
+// written against the real service can run offline.
+//
+// Use a Recorder or a Player as the Transport of the http.Client passed in ClientOptions.HTTPClient. Secrets,
+// passphrases, secret values and the Authorization header are scrubbed before anything is written to a cassette.
+// Secret keys, metadata keys and custids, in bodies and in paths, are replaced with stable placeholders such as
+// KEY_1 and CUSTID_1, which the Player maps requests to in the same way.
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the cassette file format written by Recorder. Version 2 replaced keys and custids with
+// placeholders, so cassettes of version 1 must be recorded again.
+const Version = 2
+
+// Redacted replaces every scrubbed value in a cassette
+const Redacted = "[REDACTED]"
+
+// scrubbed are the form parameters and JSON fields whose values are never written to a cassette
+var scrubbed = map[string]bool{
+	"secret":       true,
+	"passphrase":   true,
+	"value":        true,
+	"secret_value": true,
+}
+
+// identifiers are the form parameters and JSON fields that hold keys and custids, mapped to the kind of placeholder
+// that replaces their values
+var identifiers = map[string]string{
+	"secret_key":   "KEY",
+	"metadata_key": "KEY",
+	"key":          "KEY",
+	"identifier":   "KEY",
+	"custid":       "CUSTID",
+}
+
+// keyPaths are the path segments that precede a key in the path of a request, and notKeys the segments that may
+// follow them but are endpoints rather than keys
+var (
+	keyPaths = map[string]bool{"secret": true, "private": true}
+	notKeys  = map[string]bool{"recent": true, "conceal": true, "generate": true}
+)
+
+// recordedHeaders are the only headers written to a cassette, keeping credentials and cookies out of it
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and the response the service gave to it
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Only the path is kept of its URL, so that a cassette replays against any host.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load will read a cassette file
+//
+// Variables:
+//     path (string): The path of the cassette file
+//
+// Returns:
+//     (*Cassette): A pointer to the Cassette, nil if an error occurred
+//     (error):     An error if the file can not be read or is not a cassette of a known version, nil otherwise
+func Load(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(Cassette)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %v", path, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d, record it again", path, c.Version)
+	}
+	return c, nil
+}
+
+// Save will write the Cassette to a file, readable only by its owner
+//
+// Variables:
+//     path (string): The path of the cassette file
+//
+// Returns:
+//     (error): An error if the file can not be written, nil otherwise
+func (C *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(C, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// filterHeader keeps only the recordedHeaders of h
+func filterHeader(h http.Header) http.Header {
+	kept := http.Header{}
+	for _, k := range recordedHeaders {
+		if v := h[http.CanonicalHeaderKey(k)]; len(v) > 0 {
+			kept[k] = v
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// placeholders replaces keys and custids with placeholders, numbered per kind in the order they first appear, so
+// that the same value gets the same placeholder throughout a cassette
+type placeholders struct {
+	byValue map[string]string
+	last    map[string]int
+}
+
+func newPlaceholders() *placeholders {
+	return &placeholders{byValue: map[string]string{}, last: map[string]int{}}
+}
+
+// replace returns the placeholder of value. Placeholders themselves, such as those of the responses a Player
+// replays, are kept and counted, so that the next new value is numbered as it was when the cassette was recorded.
+func (P *placeholders) replace(kind, value string) string {
+	if value == "" || value == Redacted {
+		return value
+	}
+	if p, ok := P.byValue[value]; ok {
+		return p
+	}
+	if i := strings.LastIndex(value, "_"); i > 0 {
+		if n, err := strconv.Atoi(value[i+1:]); err == nil && n > 0 && identifierKind(value[:i]) {
+			if n > P.last[value[:i]] {
+				P.last[value[:i]] = n
+			}
+			P.byValue[value] = value
+			return value
+		}
+	}
+
+	P.last[kind]++
+	p := kind + "_" + strconv.Itoa(P.last[kind])
+	P.byValue[value] = p
+	return p
+}
+
+// identifierKind reports whether kind is one of the kinds of placeholder
+func identifierKind(kind string) bool {
+	for _, k := range identifiers {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubPath replaces the keys in the path of a request with their placeholders
+func scrubPath(path string, keys *placeholders) string {
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if keyPaths[segments[i-1]] && !notKeys[segments[i]] {
+			segments[i] = keys.replace("KEY", segments[i])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// scrub replaces the scrubbed values of a form or JSON body with Redacted, and the whole of a malformed one, and the
+// identifiers with their placeholders, leaving other bodies untouched
+func scrub(contentType string, body []byte, keys *placeholders) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			break
+		}
+		for _, k := range sortedKeys(values) {
+			switch {
+			case scrubbed[k]:
+				values[k] = []string{Redacted}
+			case identifiers[k] != "":
+				for i, v := range values[k] {
+					values[k][i] = keys.replace(identifiers[k], v)
+				}
+			}
+		}
+		return values.Encode()
+	case json.Valid(body):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			if b, err := json.Marshal(scrubJSON(v, keys)); err == nil {
+				return string(b)
+			}
+		}
+	}
+
+	// a form or JSON body that can not be parsed, such as a truncated one, may still hold a secret
+	if mediaType == "application/json" || mediaType == "application/x-www-form-urlencoded" {
+		return Redacted
+	}
+	return string(body)
+}
+
+// scrubJSON replaces the string values of scrubbed fields anywhere in a decoded JSON document, and those of
+// identifiers with their placeholders. Fields are visited in order, so placeholders are numbered the same way
+// every time.
+func scrubJSON(v interface{}, keys *placeholders) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for k := range v {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			child := v[k]
+			if s, ok := child.(string); ok {
+				switch {
+				case scrubbed[k]:
+					v[k] = Redacted
+					continue
+				case identifiers[k] != "":
+					v[k] = keys.replace(identifiers[k], s)
+					continue
+				}
+			}
+			v[k] = scrubJSON(child, keys)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = scrubJSON(child, keys)
+		}
+	}
+	return v
+}
+
+// sortedKeys returns the keys of a form in order
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
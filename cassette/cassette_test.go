@@ -0,0 +1,260 @@
+package cassette_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/cassette"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+const (
+	testSecret     = "hunter2 & #friends"
+	testPassphrase = "correct horse battery staple"
+	testAPIToken   = "t0ken"
+)
+
+// session is what a test does against the service: share a secret, read it back and generate another
+func session(t *testing.T, client *onetimesecret.Client) (created, generated string) {
+	t.Helper()
+	c, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: testSecret, Passphrase: testPassphrase})
+	if err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: c.SecretKey, Passphrase: testPassphrase}); err != nil {
+		t.Fatalf("RetrieveSecret: %v", err)
+	}
+	if _, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: c.MetadataKey}); err != nil {
+		t.Fatalf("RetrieveMetadata: %v", err)
+	}
+	g, err := client.GenerateSecret(&onetimesecret.GenerateSecretRequest{})
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	return c.MetadataKey, g.Value
+}
+
+// record runs the session against a fake server through a Recorder, returning the path of the saved cassette and
+// the generated secret value
+func record(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	srv := otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{"alice": testAPIToken}})
+	defer srv.Close()
+
+	path := filepath.Join(dir, "session.json")
+	rec := cassette.NewRecorder(path, srv.HTTPClient().Transport)
+	client := srv.Client(t, &onetimesecret.ClientOptions{
+		Credentials: &onetimesecret.Credentials{Username: "alice", APIToken: testAPIToken},
+		HTTPClient:  &http.Client{Transport: rec},
+	})
+
+	_, value := session(t, client)
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path, value
+}
+
+// newReplayClient returns a Client that is answered by player, against a host that does not exist
+func newReplayClient(t *testing.T, player *cassette.Player) *onetimesecret.Client {
+	t.Helper()
+	client, err := onetimesecret.NewWithOptions(&onetimesecret.ClientOptions{
+		Credentials:      &onetimesecret.Credentials{Username: "alice", APIToken: testAPIToken},
+		OneTimeSecretURL: "https://ots.invalid",
+		APIVersion:       onetimesecret.APIVersion1,
+		HTTPClient:       &http.Client{Transport: player},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, value := record(t, dir)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(b)
+
+	basic := base64.StdEncoding.EncodeToString([]byte("alice:" + testAPIToken))
+	for _, leaked := range []string{testSecret, testPassphrase, testAPIToken, basic, value, "Authorization"} {
+		if strings.Contains(content, leaked) {
+			t.Errorf("the cassette contains %q:\n%s", leaked, content)
+		}
+	}
+	if !strings.Contains(content, cassette.Redacted) {
+		t.Errorf("the cassette has no %s values:\n%s", cassette.Redacted, content)
+	}
+
+	c, err := cassette.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 4 {
+		t.Errorf("%d interactions recorded, want 4", len(c.Interactions))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, _ := record(t, dir)
+	player, err := cassette.NewPlayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newReplayClient(t, player)
+
+	metadataKey, value := session(t, client)
+	if value != cassette.Redacted {
+		t.Errorf("replayed Value = %q, want %q", value, cassette.Redacted)
+	}
+	if err := player.Done(); err != nil {
+		t.Errorf("Done: %v", err)
+	}
+
+	// every interaction is used once, so repeating a request fails
+	_, err = client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: metadataKey})
+	if err == nil || !strings.Contains(err.Error(), "no unused interaction") {
+		t.Errorf("err = %v, want no unused interaction", err)
+	}
+}
+
+func TestReplayUnmatchedRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, _ := record(t, dir)
+	player, err := cassette.NewPlayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newReplayClient(t, player)
+
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: "0123456789abcdef0123456789abcdef"}); err == nil {
+		t.Error("BurnSecret was answered by a cassette that never burned a secret")
+	} else if !strings.Contains(err.Error(), "no unused interaction") {
+		t.Errorf("err = %v, want no unused interaction", err)
+	}
+	if err := player.Done(); err == nil || !strings.Contains(err.Error(), "4 interactions were not replayed") {
+		t.Errorf("Done: err = %v, want 4 interactions not replayed", err)
+	}
+}
+
+// keySession creates, reads, generates and burns secrets, and burns one that does not exist, returning every key
+// and custid the service generated
+func keySession(t *testing.T, client *onetimesecret.Client) []string {
+	t.Helper()
+	c, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: testSecret})
+	if err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: c.SecretKey}); err != nil {
+		t.Fatalf("RetrieveSecret: %v", err)
+	}
+	g, err := client.GenerateSecret(&onetimesecret.GenerateSecretRequest{})
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if _, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: c.MetadataKey}); err != nil {
+		t.Fatalf("RetrieveMetadata: %v", err)
+	}
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: g.MetadataKey}); err != nil {
+		t.Fatalf("BurnSecret: %v", err)
+	}
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: "0123456789abcdef0123456789abcdef"}); !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+		t.Fatalf("BurnSecret of an unknown key: err = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{}); err != nil {
+		t.Fatalf("RetrieveRecentMetadata: %v", err)
+	}
+	return []string{c.MetadataKey, c.SecretKey, g.MetadataKey, g.SecretKey, c.CustID}
+}
+
+func TestRecordScrubsKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{"alice": testAPIToken}})
+	defer srv.Close()
+	path := filepath.Join(dir, "keys.json")
+	rec := cassette.NewRecorder(path, srv.HTTPClient().Transport)
+	client := srv.Client(t, &onetimesecret.ClientOptions{
+		Credentials: &onetimesecret.Credentials{Username: "alice", APIToken: testAPIToken},
+		HTTPClient:  &http.Client{Transport: rec},
+	})
+	generated := keySession(t, client)
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(b)
+	for _, leaked := range append(generated, "0123456789abcdef0123456789abcdef") {
+		if strings.Contains(content, leaked) {
+			t.Errorf("the cassette contains %q:\n%s", leaked, content)
+		}
+	}
+	for _, placeholder := range []string{"/api/v1/secret/KEY_", "/api/v1/private/KEY_", "/burn", `\"metadata_key\":\"KEY_`, `\"secret_key\":\"KEY_`, `\"custid\":\"CUSTID_1\"`} {
+		if !strings.Contains(content, placeholder) {
+			t.Errorf("the cassette has no %s:\n%s", placeholder, content)
+		}
+	}
+
+	// the same session replays, including the request for a key of its own
+	player, err := cassette.NewPlayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := keySession(t, newReplayClient(t, player))
+	if err := player.Done(); err != nil {
+		t.Errorf("Done: %v", err)
+	}
+	if replayed[0] == replayed[1] || replayed[0] == replayed[2] || !strings.HasPrefix(replayed[0], "KEY_") {
+		t.Errorf("replayed keys = %q, want distinct placeholders", replayed)
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "old.json")
+	if err := ioutil.WriteFile(path, []byte(`{"version": 1, "interactions": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cassette.Load(path); err == nil || !strings.Contains(err.Error(), "unsupported version 1") {
+		t.Errorf("err = %v, want unsupported version 1", err)
+	}
+}
@@ -0,0 +1,113 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Player is an http.RoundTripper that answers requests from a cassette instead of sending them
+//
+// A request is matched to the first unused interaction with the same method, path and scrubbed body, and every
+// interaction is used only once. The keys of a request are replaced with placeholders as they were when recording,
+// so a request for a key of a replayed response, or for a key the test chose itself, matches its recording. Every
+// interaction is used only once, so a cassette that created a secret twice replays two creations. A request that
+// matches no interaction fails with an error describing it.
+type Player struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+	keys     *placeholders
+}
+
+// NewPlayer will generate a new Player that replays a cassette file
+//
+// Variables:
+//     path (string): The path of the cassette file
+//
+// Returns:
+//     (*Player): A pointer to a new instance of Player, nil if an error occurred
+//     (error):   An error if the cassette can not be loaded, nil otherwise
+func NewPlayer(path string) (*Player, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Player{cassette: c, used: make([]bool, len(c.Interactions)), keys: newPlaceholders()}, nil
+}
+
+// RoundTrip implements http.RoundTripper, answering the request with the recorded response
+func (P *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	P.mu.Lock()
+	defer P.mu.Unlock()
+
+	path := scrubPath(req.URL.Path, P.keys)
+	scrubbedBody := scrub(req.Header.Get("Content-Type"), body, P.keys)
+	for i, interaction := range P.cassette.Interactions {
+		r := interaction.Request
+		if P.used[i] || r.Method != req.Method || r.Path != path || r.Body != scrubbedBody {
+			continue
+		}
+		P.used[i] = true
+
+		recorded := interaction.Response
+		// count the placeholders of the response, so that new keys are numbered as they were when recording
+		scrub(recorded.Header.Get("Content-Type"), []byte(recorded.Body), P.keys)
+
+		header := http.Header{}
+		for k, v := range recorded.Header {
+			header[k] = append([]string(nil), v...)
+		}
+		return &http.Response{
+			Status:        strconv.Itoa(recorded.StatusCode) + " " + http.StatusText(recorded.StatusCode),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette: no unused interaction matches %s %s with body %q", req.Method, path, scrubbedBody)
+}
+
+// Done will check that every interaction of the cassette was replayed, so that tests notice requests they stopped
+// making
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (error): An error listing the unused interactions, nil if there are none
+func (P *Player) Done() error {
+	P.mu.Lock()
+	defer P.mu.Unlock()
+
+	var unused []string
+	for i, interaction := range P.cassette.Interactions {
+		if !P.used[i] {
+			unused = append(unused, interaction.Request.Method+" "+interaction.Request.Path)
+		}
+	}
+	if len(unused) > 0 {
+		return fmt.Errorf("cassette: %d interactions were not replayed: %s", len(unused), strings.Join(unused, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cassette
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper that sends requests to the service and records every exchange, scrubbed, to a
+// cassette file
+//
+// Requests that fail without a response are passed through but not recorded.
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	keys     *placeholders
+}
+
+// NewRecorder will generate a new Recorder
+//
+// Variables:
+//     path (string):                 The path of the cassette file that Save writes
+//     transport (http.RoundTripper): The transport that actually sends the requests, nil for http.DefaultTransport
+//
+// Returns:
+//     (*Recorder): A pointer to a new instance of Recorder
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		path:      path,
+		transport: transport,
+		cassette:  Cassette{Version: Version},
+		keys:      newPlaceholders(),
+	}
+}
+
+// RoundTrip implements http.RoundTripper, sending the request and recording the exchange
+func (R *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	}
+
+	resp, err := R.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	R.mu.Lock()
+	defer R.mu.Unlock()
+	R.cassette.Interactions = append(R.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   scrubPath(req.URL.Path, R.keys),
+			Header: filterHeader(req.Header),
+			Body:   scrub(req.Header.Get("Content-Type"), reqBody, R.keys),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     filterHeader(resp.Header),
+			Body:       scrub(resp.Header.Get("Content-Type"), respBody, R.keys),
+		},
+	})
+	return resp, nil
+}
+
+// Save will write every exchange recorded so far to the cassette file
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (error): An error if the file can not be written, nil otherwise
+func (R *Recorder) Save() error {
+	R.mu.Lock()
+	defer R.mu.Unlock()
+	return R.cassette.Save(R.path)
+}
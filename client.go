@@ -1,12 +1,22 @@
 package onetimesecret
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+)
 
 // Client is the main client for performing actions against the https://onetimesecret.com/ service
 type Client struct {
-	otsURL     string
-	creds      *Credentials
-	httpClient *http.Client
+	otsURL      string
+	creds       CredentialsProvider
+	httpClient  *http.Client
+	retryPolicy *RetryPolicy
+	limiter     *RateLimiter
+	handler     Handler
+	logger      Logger
+	tracer      Tracer
+	metrics     Metrics
+	codec       codec
 }
 
 // Credentials are your https://onetimesecret.com user credentials to interact with the service API
@@ -22,37 +32,149 @@ type Credentials struct {
 
 // ClientOptions are provided to adjust and supplement the functionality of the Client
 type ClientOptions struct {
+	// OneTimeSecretURL is the base URL of the service, e.g. "https://onetimesecret.com" or a custom domain.
+	// Trailing slashes are stripped. Exactly one of OneTimeSecretURL and Region must be set.
 	OneTimeSecretURL string
 	Credentials      *Credentials
-	HTTPClient       *http.Client
+
+	// HTTPClient performs the requests. It is required.
+	HTTPClient *http.Client
+
+	// Region selects a hosted region or registered custom domain instead of setting OneTimeSecretURL.
+	Region Region
+
+	// CredentialsProvider supplies the credentials for every request, taking precedence over Credentials. The Client
+	// is anonymous when neither is set.
+	CredentialsProvider CredentialsProvider
+
+	// APIVersion selects the version of the service API to speak. It defaults to APIVersion1.
+	APIVersion APIVersion
+
+	// RetryPolicy enables retries of the idempotent calls. A nil RetryPolicy disables retries.
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter gates every request sent to the service. A nil RateLimiter sends requests unthrottled.
+	RateLimiter *RateLimiter
+
+	// Interceptors wrap every request sent to the service, the first one being the outermost.
+	Interceptors []Interceptor
+
+	// Logger receives a redacted record of every request sent to the service. A *slog.Logger can be used directly.
+	Logger Logger
+
+	// Tracer starts a span for every Client call. A nil Tracer disables tracing.
+	Tracer Tracer
+
+	// Metrics receives counters and histograms for every Client call. A nil Metrics disables metrics.
+	Metrics Metrics
 }
 
 // New will generate a new Client with the default HTTP client
 //
 // Variables:
-//     credentials (*Credentials): A pointer to a Credentials struct
+//     credentials (*Credentials): A pointer to a Credentials struct, nil for an anonymous Client
 //
 // Returns:
 //     (*Client): A pointer to a new instance of Client
 func New(credentials *Credentials) *Client {
 	var C Client
-	C.otsURL = "https://onetimesecret.com"
-	C.creds = credentials
+	C.otsURL = DefaultURL
+	if credentials != nil {
+		C.creds = credentials
+	}
 	C.httpClient = http.DefaultClient
+	C.handler = C.httpClient.Do
+	C.codec = v1Codec{}
 	return &C
 }
 
+// NewAnonymous will generate a new Client with the default HTTP client that does not authenticate
+//
+// An anonymous Client can create, generate and retrieve secrets. Calls that need an account, RetrieveMetadata,
+// BurnSecret, RetrieveRecentMetadata and Authcheck, fail with ErrAuthenticationRequired without contacting the service.
+// Passing nil Credentials to New or NewWithOptions has the same effect.
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*Client): A pointer to a new instance of Client
+func NewAnonymous() *Client {
+	return New(nil)
+}
+
 // NewWithOptions will generate a new Client with a custom HTTP Client
 //
 // Variables:
 //     opts (*ClientOptions): A pointer to a ClientOptions struct
 //
 // Returns:
-//     (*Client): A pointer to a new instance of Client
-func NewWithOptions(opts *ClientOptions) *Client {
-	var C Client
-	C.otsURL = opts.OneTimeSecretURL
-	C.creds = opts.Credentials
+//     (*Client): A pointer to a new instance of Client, nil if an error occurred
+//     (error):   An error if the options are missing the service URL or HTTP client or are invalid, nil otherwise
+func NewWithOptions(opts *ClientOptions) (*Client, error) {
+	var (
+		C   Client
+		err error
+	)
+
+	if opts == nil {
+		return nil, fmt.Errorf("ClientOptions must not be nil")
+	}
+
+	switch {
+	case opts.OneTimeSecretURL != "" && opts.Region != "":
+		return nil, fmt.Errorf("only one of OneTimeSecretURL and Region may be set")
+	case opts.Region != "":
+		C.otsURL, err = opts.Region.URL()
+	case opts.OneTimeSecretURL != "":
+		C.otsURL, err = NormalizeURL(opts.OneTimeSecretURL)
+	default:
+		err = fmt.Errorf("one of OneTimeSecretURL and Region must be set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.HTTPClient == nil {
+		return nil, fmt.Errorf("HTTPClient must not be nil")
+	}
+
+	if opts.RateLimiter != nil && opts.RateLimiter.limit <= 0 {
+		return nil, fmt.Errorf("RateLimiter must be created with NewRateLimiter")
+	}
+
+	if C.codec, err = newCodec(opts.APIVersion); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.CredentialsProvider != nil:
+		C.creds = opts.CredentialsProvider
+	case opts.Credentials != nil:
+		C.creds = opts.Credentials
+	}
 	C.httpClient = opts.HTTPClient
-	return &C
+	C.retryPolicy = opts.RetryPolicy
+	C.limiter = opts.RateLimiter
+	C.logger = opts.Logger
+	C.tracer = opts.Tracer
+	C.metrics = opts.Metrics
+
+	interceptors := opts.Interceptors
+	if C.logger != nil {
+		interceptors = append([]Interceptor{C.logRequests}, interceptors...)
+	}
+	C.handler = chain(interceptors, C.httpClient.Do)
+	return &C, nil
+}
+
+// Anonymous will report whether the Client sends requests without credentials
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (bool): true if the Client has neither Credentials nor a CredentialsProvider, false otherwise
+func (C *Client) Anonymous() bool {
+	return C.creds == nil
 }
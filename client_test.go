@@ -0,0 +1,155 @@
+package onetimesecret_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestAnonymousClient(t *testing.T) {
+	for version := range apiVersions {
+		t.Run(string(version), func(t *testing.T) {
+			srv := newTestServer()
+			defer srv.Close()
+			client := srv.Client(t, &onetimesecret.ClientOptions{APIVersion: version})
+			if !client.Anonymous() {
+				t.Fatal("Anonymous() = false for a Client without credentials")
+			}
+
+			created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", Passphrase: "pw"})
+			if err != nil {
+				t.Fatalf("CreateSecret: %v", err)
+			}
+			if created.CustID != otstest.AnonymousCustID {
+				t.Errorf("CustID = %q, want %q", created.CustID, otstest.AnonymousCustID)
+			}
+			retrieved, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey, Passphrase: "pw"})
+			if err != nil {
+				t.Fatalf("RetrieveSecret: %v", err)
+			}
+			if retrieved.SecretValue != "s3cret" {
+				t.Errorf("SecretValue = %q, want %q", retrieved.SecretValue, "s3cret")
+			}
+			generated, err := client.GenerateSecret(&onetimesecret.GenerateSecretRequest{})
+			if err != nil {
+				t.Fatalf("GenerateSecret: %v", err)
+			}
+			if generated.Value == "" || generated.CustID != otstest.AnonymousCustID {
+				t.Errorf("GenerateSecret = %+v, want an anonymous secret with a value", generated)
+			}
+			if n := srv.Requests(""); n != 3 {
+				t.Fatalf("%d requests sent, want 3", n)
+			}
+
+			// the account-only calls fail before a request is sent
+			calls := map[string]func() error{
+				"RetrieveMetadata": func() error {
+					_, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey})
+					return err
+				},
+				"BurnSecret": func() error {
+					_, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: generated.MetadataKey})
+					return err
+				},
+				"RetrieveRecentMetadata": func() error {
+					_, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+					return err
+				},
+				"Authcheck": func() error {
+					_, err := client.Authcheck()
+					return err
+				},
+			}
+			for name, call := range calls {
+				if err := call(); !errors.Is(err, onetimesecret.ErrAuthenticationRequired) {
+					t.Errorf("%s: err = %v, want ErrAuthenticationRequired", name, err)
+				}
+			}
+			if n := srv.Requests(""); n != 3 {
+				t.Errorf("%d requests sent, want none after the first 3", n-3)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *onetimesecret.ClientOptions
+		wantBase string
+		wantErr  string
+	}{
+		{name: "nil options", opts: nil, wantErr: "ClientOptions must not be nil"},
+		{
+			name:     "URL",
+			opts:     &onetimesecret.ClientOptions{OneTimeSecretURL: "https://onetimesecret.com", HTTPClient: http.DefaultClient},
+			wantBase: "https://onetimesecret.com",
+		},
+		{
+			name:     "trailing slash is stripped",
+			opts:     &onetimesecret.ClientOptions{OneTimeSecretURL: "https://example.com/ots/", HTTPClient: http.DefaultClient},
+			wantBase: "https://example.com/ots",
+		},
+		{
+			name:     "region",
+			opts:     &onetimesecret.ClientOptions{Region: onetimesecret.RegionEU, HTTPClient: http.DefaultClient},
+			wantBase: "https://eu.onetimesecret.com",
+		},
+		{
+			name:    "neither URL nor region",
+			opts:    &onetimesecret.ClientOptions{HTTPClient: http.DefaultClient},
+			wantErr: "one of OneTimeSecretURL and Region must be set",
+		},
+		{
+			name:    "both URL and region",
+			opts:    &onetimesecret.ClientOptions{OneTimeSecretURL: "https://onetimesecret.com", Region: onetimesecret.RegionEU, HTTPClient: http.DefaultClient},
+			wantErr: "only one of OneTimeSecretURL and Region may be set",
+		},
+		{
+			name:    "relative URL",
+			opts:    &onetimesecret.ClientOptions{OneTimeSecretURL: "onetimesecret.com", HTTPClient: http.DefaultClient},
+			wantErr: "scheme must be http or https",
+		},
+		{
+			name:    "non-http scheme",
+			opts:    &onetimesecret.ClientOptions{OneTimeSecretURL: "ftp://onetimesecret.com", HTTPClient: http.DefaultClient},
+			wantErr: "scheme must be http or https",
+		},
+		{
+			name:    "unknown region",
+			opts:    &onetimesecret.ClientOptions{Region: "mars", HTTPClient: http.DefaultClient},
+			wantErr: `unknown region "mars"`,
+		},
+		{
+			name:    "nil HTTPClient",
+			opts:    &onetimesecret.ClientOptions{OneTimeSecretURL: "https://onetimesecret.com"},
+			wantErr: "HTTPClient must not be nil",
+		},
+		{
+			name:    "zero RateLimiter",
+			opts:    &onetimesecret.ClientOptions{OneTimeSecretURL: "https://onetimesecret.com", HTTPClient: http.DefaultClient, RateLimiter: &onetimesecret.RateLimiter{}},
+			wantErr: "RateLimiter must be created with NewRateLimiter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := onetimesecret.NewWithOptions(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || client != nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NewWithOptions() = %v, %v, want an error containing %q", client, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewWithOptions(): %v", err)
+			}
+			if got, want := client.ShareLink("abc"), tt.wantBase+"/secret/abc"; got != want {
+				t.Errorf("ShareLink() = %q, want %q", got, want)
+			}
+		})
+	}
+}
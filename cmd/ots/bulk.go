@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// manifestColumns are the columns a bulk manifest may have
+var manifestColumns = map[string]bool{
+	"id":         true,
+	"recipient":  true,
+	"secret":     true,
+	"generate":   true,
+	"ttl":        true,
+	"passphrase": true,
+}
+
+// resultColumns are the columns of the results manifest of ots bulk, in order. The value column is only written with
+// --write-values.
+var resultColumns = []string{"id", "recipient", "metadata_key", "share_link", "value", "passphrase", "error"}
+
+func runBulk(c *cli, args []string) error {
+	var (
+		g           globalFlags
+		results     string
+		resume      bool
+		writeValues bool
+		concurrency int
+		ttl         time.Duration
+	)
+
+	fs := c.flagSet("bulk", "<manifest>", "Create the secrets of a CSV or YAML manifest and write their share links and metadata\nkeys to a results manifest. Each secret has a recipient, a secret or generate: true, a\nttl and a passphrase of none, generate or value:<passphrase>. A run that fails part way\ncan be continued with --resume, which only creates the secrets that are missing from the\nresults.")
+	g.register(fs)
+	fs.StringVar(&results, "results", "", "write the results to `path`, a .csv, .yaml or .yml file (default: <manifest>.results.<ext>)")
+	fs.BoolVar(&resume, "resume", false, "keep the secrets already created in the results and only create the others")
+	fs.BoolVar(&writeValues, "write-values", false, "also write the values of generated secrets to the results, in plaintext")
+	fs.IntVar(&concurrency, "concurrency", onetimesecret.DefaultBulkConcurrency, "number of secrets to create at once")
+	fs.DurationVar(&ttl, "ttl", 0, "how long the secrets without a ttl live (default: the service's default)")
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	if results == "" {
+		ext := filepath.Ext(path)
+		results = strings.TrimSuffix(path, ext) + ".results" + ext
+	}
+	if _, err := manifestFormat(results); err != nil {
+		return err
+	}
+
+	rows, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+	requests, err := bulkRequests(rows, ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	// the rows of a previous run, which --resume keeps when they have a metadata key
+	previous := make(map[string]manifestRow)
+	var opts onetimesecret.BulkOptions
+	opts.Concurrency = concurrency
+	switch _, err := os.Stat(results); {
+	case err == nil && !resume:
+		return usageError("%s already exists, use --resume to continue from it or remove it", results)
+	case err == nil:
+		rows, err := readManifest(results)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if row["metadata_key"] == "" || row["error"] != "" {
+				continue
+			}
+			previous[row["id"]] = row
+			opts.Previous = append(opts.Previous, onetimesecret.BulkResult{
+				ID:       row["id"],
+				Metadata: &onetimesecret.Metadata{MetadataKey: row["metadata_key"]},
+			})
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	client, err := c.client(&g, "")
+	if err != nil {
+		return err
+	}
+
+	columns := resultColumns
+	if !writeValues {
+		columns = withoutColumn(resultColumns, "value")
+	}
+
+	// the results are saved after every secret, so that the links of the secrets created so far survive a crash
+	var (
+		byID     = rowsByID(rows, requests)
+		out      = make(map[string]manifestRow, len(requests))
+		failed   int
+		resumed  int
+		writeErr error
+	)
+	opts.OnResult = func(r onetimesecret.BulkResult) {
+		row := previous[r.ID]
+		switch {
+		case r.Resumed:
+			resumed++
+		case r.Err != nil:
+			failed++
+			row = manifestRow{"id": r.ID, "error": r.Err.Error()}
+			fmt.Fprintf(c.stderr, "ots bulk: %s: %v\n", r.ID, r.Err)
+		default:
+			row = manifestRow{
+				"id":           r.ID,
+				"metadata_key": r.Metadata.MetadataKey,
+				"share_link":   r.Metadata.ShareLink(),
+				"passphrase":   r.Passphrase,
+			}
+			if writeValues {
+				row["value"] = r.Value
+			}
+		}
+		row["recipient"] = byID[r.ID]["recipient"]
+		out[r.ID] = row
+
+		ordered := make([]manifestRow, 0, len(out))
+		for i := range requests {
+			if row, ok := out[requests[i].ID]; ok {
+				ordered = append(ordered, row)
+			}
+		}
+		if err := writeManifest(results, columns, ordered); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+
+	if _, err := client.Bulk(c.ctx, requests, &opts); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return fmt.Errorf("writing the results: %v", writeErr)
+	}
+
+	fmt.Fprintf(c.stderr, "Created %d secrets, %d failed, %d already created. Results written to %s\n",
+		len(requests)-failed-resumed, failed, resumed, results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d secrets were not created, run again with --resume to retry them", failed, len(requests))
+	}
+	return nil
+}
+
+// bulkRequests converts the rows of a manifest into the requests of Client.Bulk, giving every row an ID
+func bulkRequests(rows []manifestRow, ttl time.Duration) ([]onetimesecret.BulkRequest, error) {
+	requests := make([]onetimesecret.BulkRequest, len(rows))
+	for i, row := range rows {
+		for column := range row {
+			if !manifestColumns[column] {
+				return nil, fmt.Errorf("secret %d: unknown column %q", i+1, column)
+			}
+		}
+
+		r := &requests[i]
+		r.ID = row["id"]
+		if r.ID == "" {
+			r.ID = strconv.Itoa(i + 1)
+		}
+		if row["recipient"] != "" {
+			r.Recipient = []string{row["recipient"]}
+		}
+
+		generate := false
+		if row["generate"] != "" {
+			var err error
+			if generate, err = strconv.ParseBool(row["generate"]); err != nil {
+				return nil, fmt.Errorf("secret %s: generate must be true or false", r.ID)
+			}
+		}
+		switch {
+		case generate && row["secret"] != "":
+			return nil, fmt.Errorf("secret %s: a secret can not be given with generate: true", r.ID)
+		case !generate && row["secret"] == "":
+			return nil, fmt.Errorf("secret %s: a secret or generate: true is needed", r.ID)
+		}
+		r.Secret = row["secret"]
+
+		r.TTL = ttl
+		if row["ttl"] != "" {
+			var err error
+			if r.TTL, err = time.ParseDuration(row["ttl"]); err != nil {
+				return nil, fmt.Errorf("secret %s: ttl must be a duration, e.g. 30m or 168h", r.ID)
+			}
+		}
+
+		switch p := row["passphrase"]; {
+		case p == "" || p == "none":
+		case p == "generate":
+			r.GeneratePassphrase = true
+		case strings.HasPrefix(p, "value:") && len(p) > len("value:"):
+			r.Passphrase = strings.TrimPrefix(p, "value:")
+		default:
+			return nil, fmt.Errorf("secret %s: passphrase must be none, generate or value:<passphrase>", r.ID)
+		}
+	}
+	return requests, nil
+}
+
+// rowsByID maps the IDs of requests onto the rows of the manifest they were read from
+func rowsByID(rows []manifestRow, requests []onetimesecret.BulkRequest) map[string]manifestRow {
+	m := make(map[string]manifestRow, len(rows))
+	for i := range rows {
+		m[requests[i].ID] = rows[i]
+	}
+	return m
+}
+
+// withoutColumn returns columns without the column name
+func withoutColumn(columns []string, name string) []string {
+	out := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column != name {
+			out = append(out, column)
+		}
+	}
+	return out
+}
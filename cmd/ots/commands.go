@@ -0,0 +1,282 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// secretFlags are the flags of the commands that create a secret
+type secretFlags struct {
+	ttl            time.Duration
+	passphraseFile string
+	recipients     stringsFlag
+}
+
+func (S *secretFlags) register(fs *flag.FlagSet) {
+	fs.DurationVar(&S.ttl, "ttl", 0, "how long the secret lives, e.g. 30m or 168h (default: the service's default)")
+	fs.StringVar(&S.passphraseFile, "passphrase-file", "", "read a passphrase the recipient must enter from `path`")
+	fs.Var(&S.recipients, "recipient", "email `address` to send the share link to, may be repeated")
+}
+
+func runShare(c *cli, args []string) error {
+	var (
+		g    globalFlags
+		s    secretFlags
+		o    outputFlags
+		file string
+	)
+
+	fs := c.flagSet("share", "", "Share a secret read from stdin or --file and print its share link. A single trailing\nnewline is removed from the secret.")
+	g.register(fs)
+	o.register(fs)
+	s.register(fs)
+	fs.StringVar(&file, "file", "", "read the secret from `path` instead of stdin")
+	if err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	secret, err := c.readSecret(file)
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(s.passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, "")
+	if err != nil {
+		return err
+	}
+	resp, err := client.CreateSecretWithContext(c.ctx, &onetimesecret.CreateSecretRequest{
+		Secret:     secret,
+		Passphrase: passphrase,
+		TTL:        s.ttl,
+		Recipient:  s.recipients,
+	})
+	if err != nil {
+		return err
+	}
+
+	if ok, err := c.printSecret(&o, &resp.Metadata, ""); ok || err != nil {
+		return err
+	}
+	c.printCreated(&resp.Metadata)
+	return nil
+}
+
+func runGenerate(c *cli, args []string) error {
+	var (
+		g globalFlags
+		s secretFlags
+		o outputFlags
+	)
+
+	fs := c.flagSet("generate", "", "Generate a random secret, share it and print its share link. The generated value is\nprinted to stderr.")
+	g.register(fs)
+	o.register(fs)
+	s.register(fs)
+	if err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase(s.passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, "")
+	if err != nil {
+		return err
+	}
+	resp, err := client.GenerateSecretWithContext(c.ctx, &onetimesecret.GenerateSecretRequest{
+		Passphrase: passphrase,
+		TTL:        s.ttl,
+		Recipient:  s.recipients,
+	})
+	if err != nil {
+		return err
+	}
+
+	if ok, err := c.printSecret(&o, &resp.Metadata, resp.Value); ok || err != nil {
+		return err
+	}
+	c.printCreated(&resp.Metadata)
+	fmt.Fprintf(c.stderr, "Value: %s\n", resp.Value)
+	return nil
+}
+
+func runGet(c *cli, args []string) error {
+	var (
+		g              globalFlags
+		passphraseFile string
+	)
+
+	fs := c.flagSet("get", "<share-link|secret-key>", "Retrieve a secret and write it to stdout, exactly as it was shared. A secret can only be\nretrieved once.")
+	g.register(fs)
+	fs.StringVar(&passphraseFile, "passphrase-file", "", "read the passphrase of the secret from `path`")
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	baseURL, key, err := parseTarget(fs.Arg(0), onetimesecret.LinkSecret)
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, baseURL)
+	if err != nil {
+		return err
+	}
+	resp, err := client.RetrieveSecretWithContext(c.ctx, &onetimesecret.RetrieveSecretRequest{
+		SecretKey:  key,
+		Passphrase: passphrase,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(c.stdout, resp.SecretValue)
+	return err
+}
+
+func runMeta(c *cli, args []string) error {
+	var (
+		g globalFlags
+		o outputFlags
+	)
+
+	fs := c.flagSet("meta", "<private-link|metadata-key>", "Show the metadata of a secret, including whether it has been received.")
+	g.register(fs)
+	o.register(fs)
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	baseURL, key, err := parseTarget(fs.Arg(0), onetimesecret.LinkPrivate)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, baseURL)
+	if err != nil {
+		return err
+	}
+	resp, err := client.RetrieveMetadataWithContext(c.ctx, &onetimesecret.RetrieveMetadataRequest{MetadataKey: key})
+	if err != nil {
+		return err
+	}
+
+	if ok, err := c.printSecret(&o, &resp.Metadata, ""); ok || err != nil {
+		return err
+	}
+	c.printMetadata(&resp.Metadata)
+	return nil
+}
+
+func runBurn(c *cli, args []string) error {
+	var (
+		g globalFlags
+		o outputFlags
+	)
+
+	fs := c.flagSet("burn", "<private-link|metadata-key>", "Destroy a secret so that it can no longer be retrieved.")
+	g.register(fs)
+	o.register(fs)
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	baseURL, key, err := parseTarget(fs.Arg(0), onetimesecret.LinkPrivate)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, baseURL)
+	if err != nil {
+		return err
+	}
+	resp, err := client.BurnSecretWithContext(c.ctx, &onetimesecret.BurnSecretRequest{MetadataKey: key})
+	if err != nil {
+		return err
+	}
+
+	if ok, err := c.printSecret(&o, &resp.Metadata, ""); ok || err != nil {
+		return err
+	}
+	c.printMetadata(&resp.Metadata)
+	return nil
+}
+
+func runRecent(c *cli, args []string) error {
+	var (
+		g globalFlags
+		o outputFlags
+	)
+
+	fs := c.flagSet("recent", "", "List the recent secrets of your account. It needs credentials.")
+	g.register(fs)
+	o.register(fs)
+	if err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, "")
+	if err != nil {
+		return err
+	}
+	resp, err := client.RetrieveRecentMetadataWithContext(c.ctx, &onetimesecret.RetrieveRecentMetadataRequest{})
+	if err != nil {
+		return err
+	}
+
+	return c.printSecrets(&o, *resp)
+}
+
+func runStatus(c *cli, args []string) error {
+	var g globalFlags
+
+	fs := c.flagSet("status", "", "Check that the service is up. It exits with status 1 unless the service is nominal.")
+	g.register(fs)
+	if err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, "")
+	if err != nil {
+		return err
+	}
+	resp, err := client.StatusWithContext(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.stdout, resp.Status)
+	if !resp.Nominal() {
+		return &exitError{code: 1}
+	}
+	return nil
+}
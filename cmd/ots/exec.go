@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// passphraseAttempts is how many times ots exec asks for the passphrase of a secret before giving up
+const passphraseAttempts = 3
+
+// envName matches the names of environment variables that shells accept
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envMapping is a variable of ots exec and the secret it is set to
+type envMapping struct {
+	name    string
+	target  string
+	baseURL string
+	key     string
+}
+
+func runExec(c *cli, args []string) error {
+	var (
+		g   globalFlags
+		env stringsFlag
+	)
+
+	fs := c.flagSet("exec", "-- <command> [arguments]", "Retrieve secrets and run a command with them in its environment. The values are only\never passed to the command, never printed or written to disk. Secrets that need a\npassphrase prompt for it. Signals are passed on to the command, and ots exits with its\nexit status.")
+	g.register(fs)
+	fs.Var(&env, "env", "set a variable of the command to a secret, as `NAME=<share-link|secret-key>`, may be repeated")
+	if err := c.parse(fs, args, -1); err != nil {
+		return err
+	}
+	if len(env) == 0 {
+		return usageError("at least one --env is needed")
+	}
+
+	mappings, err := parseEnvMappings(env)
+	if err != nil {
+		return err
+	}
+
+	// every secret is retrieved before the command starts, so that it never runs with part of its environment. The
+	// credentials of ots are not passed on, only the secrets the command is given.
+	environ := unsetEnv(unsetEnv(os.Environ(), onetimesecret.EnvUsername), onetimesecret.EnvAPIToken)
+	for i, m := range mappings {
+		value, err := c.retrieveForExec(&g, m)
+		if err != nil {
+			if i > 0 {
+				fmt.Fprintf(c.stderr, "ots exec: %s were already retrieved and can not be retrieved again, the command was not run\n", retrievedNames(mappings[:i]))
+			}
+			return fmt.Errorf("%s: %v", m.name, err)
+		}
+		environ = setEnv(environ, m.name, value)
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Env = environ
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return &exitError{code: 127, err: err}
+		}
+		return &exitError{code: 126, err: err}
+	}
+
+	if len(forwardSignals) > 0 {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, forwardSignals...)
+		defer signal.Stop(signals)
+		go func() {
+			for s := range signals {
+				cmd.Process.Signal(s)
+			}
+		}()
+	}
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		// the convention of shells for a command killed by a signal
+		return &exitError{code: 128 + int(status.Signal())}
+	}
+	return &exitError{code: exitErr.ExitCode()}
+}
+
+// parseEnvMappings reads the NAME=<share-link|secret-key> values of --env
+func parseEnvMappings(env []string) ([]envMapping, error) {
+	var (
+		mappings []envMapping
+		seen     = make(map[string]bool)
+	)
+	for _, e := range env {
+		i := strings.Index(e, "=")
+		if i < 0 {
+			return nil, usageError("--env %q must be NAME=<share-link|secret-key>", e)
+		}
+		m := envMapping{name: e[:i], target: e[i+1:]}
+		if !envName.MatchString(m.name) {
+			return nil, usageError("--env %q: %q is not a valid variable name", e, m.name)
+		}
+		if seen[m.name] {
+			return nil, usageError("--env sets %s more than once", m.name)
+		}
+		seen[m.name] = true
+
+		var err error
+		if m.baseURL, m.key, err = parseTarget(m.target, onetimesecret.LinkSecret); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// retrieveForExec retrieves the secret of m, asking for its passphrase on the terminal when it needs one
+func (C *cli) retrieveForExec(g *globalFlags, m envMapping) (string, error) {
+	client, err := C.client(g, m.baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		passphrase string
+		tty        *cli
+		in         *bufio.Reader
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err := client.RetrieveSecretWithContext(C.ctx, &onetimesecret.RetrieveSecretRequest{
+			SecretKey:  m.key,
+			Passphrase: passphrase,
+		})
+		if err == nil {
+			return resp.SecretValue, nil
+		}
+		if !errors.Is(err, onetimesecret.ErrPassphraseRequired) || attempt == passphraseAttempts {
+			return "", err
+		}
+
+		// the command owns stdin, so not a byte of it may be read: the passphrase is read from the terminal itself
+		if tty == nil {
+			f, err := openTerminal()
+			if err != nil {
+				return "", fmt.Errorf("the secret needs a passphrase, which can only be entered on a terminal")
+			}
+			defer f.Close()
+			tty = &cli{ctx: C.ctx, stdin: f, stdout: C.stdout, stderr: C.stderr}
+			in = bufio.NewReader(f)
+		}
+		if attempt > 0 {
+			fmt.Fprintln(C.stderr, "Incorrect passphrase.")
+		}
+		if passphrase, err = tty.prompt(in, fmt.Sprintf("Passphrase for %s: ", m.name), true); err != nil {
+			return "", err
+		}
+	}
+}
+
+// retrievedNames lists the variables of mappings for a message
+func retrievedNames(mappings []envMapping) string {
+	names := make([]string, len(mappings))
+	for i, m := range mappings {
+		names[i] = m.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// setEnv sets the variable name in environ, replacing any value it already has
+func setEnv(environ []string, name, value string) []string {
+	return append(unsetEnv(environ, name), name+"="+value)
+}
+
+// unsetEnv removes the variable name from environ
+func unsetEnv(environ []string, name string) []string {
+	out := environ[:0:0]
+	for _, e := range environ {
+		if !strings.HasPrefix(e, name+"=") {
+			out = append(out, e)
+		}
+	}
+	return out
+}
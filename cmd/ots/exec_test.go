@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// TestHelperProcess is the command run by the ots exec tests. It is not a test of its own.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("OTS_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		os.Exit(2)
+	}
+
+	switch args[1] {
+	case "env":
+		// prints the variables named by the other arguments, "NAME unset" for those that are not set
+		for _, name := range args[2:] {
+			if value, ok := os.LookupEnv(name); ok {
+				fmt.Printf("%s=%s\n", name, value)
+			} else {
+				fmt.Printf("%s unset\n", name)
+			}
+		}
+	case "cat":
+		io.Copy(os.Stdout, os.Stdin)
+	case "exit":
+		code, _ := strconv.Atoi(args[2])
+		fmt.Fprintf(os.Stderr, "exiting with %d\n", code)
+		os.Exit(code)
+	}
+	os.Exit(0)
+}
+
+// helperCommand returns the arguments of ots exec that run TestHelperProcess in the given mode
+func helperCommand(args ...string) []string {
+	return append([]string{"--", os.Args[0], "-test.run=^TestHelperProcess$", "--"}, args...)
+}
+
+func TestExec(t *testing.T) {
+	_, cleanup := hermetic(t)
+	defer cleanup()
+	defer setenv(onetimesecret.EnvUsername, "alice")()
+	defer setenv(onetimesecret.EnvAPIToken, "token")()
+	defer setenv("OTS_TEST_HELPER_PROCESS", "1")()
+	// the race detector otherwise sleeps for a second before the helper exits
+	defer setenv("GORACE", "atexit_sleep_ms=0")()
+	defer setenv("KEPT", "kept")()
+	defer setenv("DB_PASSWORD", "replaced")()
+
+	srv := otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{"alice": "token"}})
+	defer srv.Close()
+	client := srv.Client(t, nil)
+	share := func(t *testing.T, secret, passphrase string) *onetimesecret.CreateSecretResponse {
+		resp, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: secret, Passphrase: passphrase, TTL: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	t.Run("env", func(t *testing.T) {
+		db, token := share(t, "s3cret", ""), share(t, "other=value\nlines", "")
+		args := append([]string{"exec", "--url", srv.URL,
+			"--env", "DB_PASSWORD=" + db.ShareLink(),
+			"--env", "API_TOKEN=" + token.SecretKey,
+		}, helperCommand("env", "DB_PASSWORD", "API_TOKEN", "KEPT", onetimesecret.EnvUsername, onetimesecret.EnvAPIToken)...)
+
+		code, stdout, stderr := runCLI("", args...)
+		if code != 0 {
+			t.Fatalf("exit status = %d, want 0, stderr: %s", code, stderr)
+		}
+		want := "DB_PASSWORD=s3cret\nAPI_TOKEN=other=value\nlines\nKEPT=kept\nOTS_USERNAME unset\nOTS_APITOKEN unset\n"
+		if stdout != want {
+			t.Errorf("environment of the command =\n%s\nwant\n%s", stdout, want)
+		}
+		if stderr != "" {
+			t.Errorf("stderr = %q, want nothing", stderr)
+		}
+	})
+
+	t.Run("credentials set with --env", func(t *testing.T) {
+		token := share(t, "child-token", "")
+		args := append([]string{"exec", "--url", srv.URL, "--env", "OTS_APITOKEN=" + token.SecretKey},
+			helperCommand("env", onetimesecret.EnvUsername, onetimesecret.EnvAPIToken)...)
+		code, stdout, stderr := runCLI("", args...)
+		if want := "OTS_USERNAME unset\nOTS_APITOKEN=child-token\n"; code != 0 || stdout != want {
+			t.Errorf("exit status, stdout = %d, %q, want 0, %q, stderr: %s", code, stdout, want, stderr)
+		}
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		secret := share(t, "s3cret", "")
+		args := append([]string{"exec", "--url", srv.URL, "--env", "S=" + secret.SecretKey}, helperCommand("cat")...)
+		code, stdout, stderr := runCLI("line 1\nline 2\n", args...)
+		if want := "line 1\nline 2\n"; code != 0 || stdout != want {
+			t.Errorf("exit status, stdout = %d, %q, want 0, %q, stderr: %s", code, stdout, want, stderr)
+		}
+	})
+
+	for _, exit := range []int{0, 1, 3, 42} {
+		t.Run(fmt.Sprintf("exit status %d", exit), func(t *testing.T) {
+			secret := share(t, "s3cret", "")
+			args := append([]string{"exec", "--url", srv.URL, "--env", "S=" + secret.SecretKey}, helperCommand("exit", strconv.Itoa(exit))...)
+			code, stdout, stderr := runCLI("", args...)
+			if code != exit {
+				t.Errorf("exit status = %d, want %d", code, exit)
+			}
+			if want := fmt.Sprintf("exiting with %d\n", exit); stdout != "" || stderr != want {
+				t.Errorf("stdout, stderr = %q, %q, want nothing and %q", stdout, stderr, want)
+			}
+		})
+	}
+
+	t.Run("command not found", func(t *testing.T) {
+		secret := share(t, "s3cret", "")
+		code, _, stderr := runCLI("", "exec", "--url", srv.URL, "--env", "S="+secret.SecretKey, "--", "ots-test-no-such-command")
+		if code != 127 || !strings.Contains(stderr, "ots-test-no-such-command") {
+			t.Errorf("exit status, stderr = %d, %q, want 127 and the command", code, stderr)
+		}
+	})
+
+	t.Run("passphrase without a terminal", func(t *testing.T) {
+		if f, err := openTerminal(); err == nil {
+			f.Close()
+			t.Skip("the test runs on a terminal")
+		}
+		secret := share(t, "s3cret", "pw")
+		args := append([]string{"exec", "--url", srv.URL, "--env", "S=" + secret.SecretKey}, helperCommand("cat")...)
+		code, stdout, stderr := runCLI("pw\n", args...)
+		if code != 1 || stdout != "" || !strings.Contains(stderr, "can only be entered on a terminal") {
+			t.Errorf("exit status, stdout, stderr = %d, %q, %q, want 1 and no passphrase read from stdin", code, stdout, stderr)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+const (
+	// envURL is the environment variable the --url flag defaults to
+	envURL = "OTS_URL"
+
+	// envRegion is the environment variable the --region flag defaults to
+	envRegion = "OTS_REGION"
+)
+
+// stringsFlag is a flag that may be repeated, collecting every value
+type stringsFlag []string
+
+func (S *stringsFlag) String() string {
+	return strings.Join(*S, ",")
+}
+
+func (S *stringsFlag) Set(value string) error {
+	*S = append(*S, value)
+	return nil
+}
+
+// globalFlags are the flags shared by every command that talks to the service
+type globalFlags struct {
+	profile string
+	url     string
+	region  string
+}
+
+func (G *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&G.profile, "profile", "", "`name` of the profile of the config file to use (env "+onetimesecret.EnvProfile+", default \""+onetimesecret.DefaultProfile+"\")")
+	fs.StringVar(&G.url, "url", os.Getenv(envURL), "base `URL` of the service, e.g. a self-hosted deployment (env "+envURL+")")
+	fs.StringVar(&G.region, "region", os.Getenv(envRegion), "hosted `region` of the service: us, eu, ca or nz (env "+envRegion+")")
+}
+
+// flagSet creates the flags of a command, with a usage message naming its arguments
+func (C *cli) flagSet(name, arguments, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet("ots "+name, flag.ContinueOnError)
+	fs.SetOutput(C.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(C.stderr, "Usage: %s\n\n%s\n\nFlags:\n", strings.TrimSpace("ots "+name+" [flags] "+arguments), description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags of a command and checks that exactly nargs arguments follow them, or at least one if nargs
+// is negative
+func (C *cli) parse(fs *flag.FlagSet, args []string, nargs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &exitError{code: 2}
+	}
+	if (nargs < 0 && fs.NArg() == 0) || (nargs >= 0 && fs.NArg() != nargs) {
+		fs.Usage()
+		return &exitError{code: 2}
+	}
+	return nil
+}
+
+// loadProfile loads the profile selected by the flags, or an empty one if the default profile is selected but does not
+// exist. It also reports whether the profile was selected explicitly, with --profile or OTS_PROFILE, and exists.
+func (G *globalFlags) loadProfile() (*onetimesecret.Profile, bool, error) {
+	explicit := G.profile != "" || os.Getenv(onetimesecret.EnvProfile) != ""
+	profile, err := onetimesecret.LoadProfile("", G.profile)
+	if errors.Is(err, onetimesecret.ErrProfileNotFound) && onetimesecret.ProfileName(G.profile) == onetimesecret.DefaultProfile {
+		return &onetimesecret.Profile{Name: onetimesecret.DefaultProfile}, false, nil
+	}
+	return profile, explicit && err == nil, err
+}
+
+// client creates the Client of a command
+//
+// The Client talks to the service chosen by the flags, or else by the profile. It authenticates with the credentials
+// of the OTS_USERNAME and OTS_APITOKEN environment variables, or else of the profile, and is anonymous when there
+// are none. A profile selected with --profile or OTS_PROFILE only uses its own credentials, as the variables may
+// belong to another service, unless the service is the one OTS_URL names.
+//
+// baseURL is set for a link that may belong to another service. The Client then talks to that service, and only
+// authenticates if it is the service it would have talked to anyway, so that credentials are never sent to the host
+// of an arbitrary link.
+func (C *cli) client(g *globalFlags, baseURL string) (*onetimesecret.Client, error) {
+	profile, selected, err := g.loadProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := profile.ClientOptions()
+	opts.RetryPolicy = onetimesecret.DefaultRetryPolicy()
+	if g.url != "" || g.region != "" {
+		opts.OneTimeSecretURL = g.url
+		opts.Region = onetimesecret.Region(g.region)
+	}
+
+	var provider onetimesecret.ChainProvider
+	envTied, err := envService(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !selected || envTied {
+		provider = append(provider, onetimesecret.EnvProvider{})
+	}
+	if opts.Credentials != nil {
+		provider = append(provider, opts.Credentials)
+	}
+	opts.Credentials = nil
+
+	if baseURL != "" {
+		serviceURL, err := serviceURL(opts)
+		if err != nil {
+			return nil, err
+		}
+		if baseURL != serviceURL {
+			provider = nil
+		}
+		opts.OneTimeSecretURL = baseURL
+		opts.Region = ""
+	}
+
+	_, err = provider.Retrieve(C.ctx)
+	switch {
+	case err == nil:
+		opts.CredentialsProvider = provider
+	case !errors.Is(err, onetimesecret.ErrNoCredentials):
+		return nil, err
+	}
+
+	return onetimesecret.NewWithOptions(opts)
+}
+
+// serviceURL returns the normalized base URL of the service that opts choose
+func serviceURL(opts *onetimesecret.ClientOptions) (string, error) {
+	if opts.Region != "" {
+		return opts.Region.URL()
+	}
+	return onetimesecret.NormalizeURL(opts.OneTimeSecretURL)
+}
+
+// envService reports whether opts choose the service that OTS_URL names, the one the credentials of the environment
+// belong to
+func envService(opts *onetimesecret.ClientOptions) (bool, error) {
+	if os.Getenv(envURL) == "" {
+		return false, nil
+	}
+	want, err := onetimesecret.NormalizeURL(os.Getenv(envURL))
+	if err != nil {
+		return false, nil
+	}
+	got, err := serviceURL(opts)
+	if err != nil {
+		return false, err
+	}
+	return got == want, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// TestClientCredentials checks which credentials the Client of a command authenticates with
+func TestClientCredentials(t *testing.T) {
+	srv := otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{
+		"env":     "env-token",
+		"default": "default-token",
+		"work":    "work-token",
+	}})
+	defer srv.Close()
+	other := otstest.NewServer(nil)
+	defer other.Close()
+
+	tests := []struct {
+		name       string
+		profiles   []*onetimesecret.Profile
+		env        bool
+		envURL     string
+		profile    string
+		envProfile string
+		url        string
+		want       string
+	}{
+		{name: "environment", env: true, want: "env"},
+		{name: "nothing", want: ""},
+		{
+			name:     "default profile",
+			profiles: []*onetimesecret.Profile{{Name: "default", URL: srv.URL, Username: "default", APIToken: "default-token"}},
+			want:     "default",
+		},
+		{
+			name:     "environment over the default profile",
+			profiles: []*onetimesecret.Profile{{Name: "default", URL: srv.URL, Username: "default", APIToken: "default-token"}},
+			env:      true,
+			want:     "env",
+		},
+		{
+			name:     "--profile over the environment",
+			profiles: []*onetimesecret.Profile{{Name: "work", URL: srv.URL, Username: "work", APIToken: "work-token"}},
+			env:      true,
+			profile:  "work",
+			want:     "work",
+		},
+		{
+			name:       "OTS_PROFILE over the environment",
+			profiles:   []*onetimesecret.Profile{{Name: "work", URL: srv.URL, Username: "work", APIToken: "work-token"}},
+			env:        true,
+			envProfile: "work",
+			want:       "work",
+		},
+		{
+			name:     "--profile without credentials is anonymous",
+			profiles: []*onetimesecret.Profile{{Name: "work", URL: srv.URL}},
+			env:      true,
+			profile:  "work",
+			want:     "",
+		},
+		{
+			name:     "--profile of the service of OTS_URL",
+			profiles: []*onetimesecret.Profile{{Name: "work", Username: "work", APIToken: "work-token"}},
+			env:      true,
+			envURL:   srv.URL,
+			profile:  "work",
+			want:     "env",
+		},
+		{
+			name:     "--profile of another service than OTS_URL",
+			profiles: []*onetimesecret.Profile{{Name: "work", Username: "work", APIToken: "work-token"}},
+			env:      true,
+			envURL:   other.URL,
+			profile:  "work",
+			url:      srv.URL,
+			want:     "work",
+		},
+		{
+			name:    "--profile default that does not exist",
+			env:     true,
+			profile: "default",
+			want:    "env",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cleanup := hermetic(t)
+			defer cleanup()
+			for _, p := range tt.profiles {
+				if err := onetimesecret.SaveProfile("", p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.env {
+				defer setenv(onetimesecret.EnvUsername, "env")()
+				defer setenv(onetimesecret.EnvAPIToken, "env-token")()
+			}
+			if tt.envProfile != "" {
+				defer setenv(onetimesecret.EnvProfile, tt.envProfile)()
+			}
+			if tt.envURL != "" {
+				defer setenv(envURL, tt.envURL)()
+			}
+
+			// the --url flag defaults to OTS_URL, and to the URL of the server when the profile names none
+			g := &globalFlags{profile: tt.profile, url: tt.url}
+			if g.url == "" {
+				g.url = tt.envURL
+			}
+			if g.url == "" && (len(tt.profiles) == 0 || tt.profiles[0].URL == "") {
+				g.url = srv.URL
+			}
+			c := &cli{ctx: context.Background(), stdout: ioutil.Discard, stderr: ioutil.Discard}
+			client, err := c.client(g, "")
+			if err != nil {
+				t.Fatalf("client(): %v", err)
+			}
+
+			if tt.want == "" {
+				if !client.Anonymous() {
+					t.Error("the Client authenticates, want it anonymous")
+				}
+				return
+			}
+			auth, err := client.Authcheck()
+			if err != nil {
+				t.Fatalf("Authcheck: %v", err)
+			}
+			if auth.CustID != tt.want {
+				t.Errorf("the Client authenticates as %q, want %q", auth.CustID, tt.want)
+			}
+		})
+	}
+}
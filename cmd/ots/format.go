@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// schemaVersion is the version of the JSON schema of secretView and secretListView. It only changes when a field is
+// removed or changes meaning; fields may be added within a version.
+const schemaVersion = 1
+
+// secretView is the stable, documented form of a secret printed by --output json, yaml and env and passed to
+// --template. It is versioned separately from the JSON of the service.
+type secretView struct {
+	SchemaVersion      int        `json:"schema_version,omitempty"`
+	MetadataKey        string     `json:"metadata_key"`
+	SecretKey          string     `json:"secret_key"`
+	State              string     `json:"state"`
+	ShareLink          string     `json:"share_link"`
+	PrivateLink        string     `json:"private_link"`
+	Value              string     `json:"value,omitempty"`
+	Recipients         []string   `json:"recipients"`
+	PassphraseRequired bool       `json:"passphrase_required"`
+	TTLSeconds         int64      `json:"ttl_seconds"`
+	CreatedAt          *time.Time `json:"created_at"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	ReceivedAt         *time.Time `json:"received_at"`
+}
+
+// secretListView is the stable form of a list of secrets
+type secretListView struct {
+	SchemaVersion int          `json:"schema_version"`
+	Secrets       []secretView `json:"secrets"`
+}
+
+// newSecretView converts the metadata of a secret, and the value of a generated one, into its stable form
+func newSecretView(m *onetimesecret.Metadata, value string) secretView {
+	v := secretView{
+		MetadataKey:        m.MetadataKey,
+		SecretKey:          m.SecretKey,
+		State:              string(m.State),
+		ShareLink:          m.ShareLink(),
+		PrivateLink:        m.PrivateLink(),
+		Value:              value,
+		Recipients:         m.Recipient,
+		PassphraseRequired: m.PassphraseRequired,
+		CreatedAt:          timePtr(m.CreatedAt),
+		ExpiresAt:          timePtr(expiresAt(m)),
+		ReceivedAt:         timePtr(m.Received),
+	}
+	if v.Recipients == nil {
+		v.Recipients = []string{}
+	}
+	// the service only reports the remaining lifetime of a secret that can still be retrieved
+	if !m.State.Final() {
+		v.TTLSeconds = int64(m.SecretTTL / time.Second)
+	}
+	return v
+}
+
+// timePtr returns t in UTC to the second, or nil for the zero time.Time so that it prints as null
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC().Truncate(time.Second)
+	return &t
+}
+
+// outputFlags are the flags of the commands that print secrets
+type outputFlags struct {
+	format   string
+	template string
+}
+
+func (O *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&O.format, "output", "", "print the result as `format`: json, yaml, table or env")
+	fs.StringVar(&O.template, "template", "", "print the result with a Go text/template, e.g. '{{.share_link}}', see the JSON schema for the fields")
+}
+
+// check rejects an unknown format
+func (O *outputFlags) check() error {
+	switch O.format {
+	case "", "json", "yaml", "table", "env":
+		return nil
+	}
+	return usageError("unknown output format %q, use json, yaml, table or env", O.format)
+}
+
+// printSecret prints one secret in the format chosen by the flags, returning false if no format was chosen
+func (C *cli) printSecret(o *outputFlags, m *onetimesecret.Metadata, value string) (bool, error) {
+	v := newSecretView(m, value)
+	v.SchemaVersion = schemaVersion
+
+	switch {
+	case o.template != "":
+		return true, C.printTemplate(o.template, v)
+	case o.format == "json":
+		return true, printJSON(C.stdout, v)
+	case o.format == "yaml":
+		return true, printYAML(C.stdout, reflect.ValueOf(v), "", "")
+	case o.format == "env":
+		return true, printEnv(C.stdout, v)
+	case o.format == "table":
+		C.printMetadata(m)
+		return true, nil
+	}
+	return false, nil
+}
+
+// printSecrets prints a list of secrets in the format chosen by the flags, as a table if none was chosen
+func (C *cli) printSecrets(o *outputFlags, list []onetimesecret.Metadata) error {
+	v := secretListView{SchemaVersion: schemaVersion, Secrets: make([]secretView, 0, len(list))}
+	for i := range list {
+		v.Secrets = append(v.Secrets, newSecretView(&list[i], ""))
+	}
+
+	switch {
+	case o.template != "":
+		return C.printTemplate(o.template, v)
+	case o.format == "json":
+		return printJSON(C.stdout, v)
+	case o.format == "yaml":
+		return printYAML(C.stdout, reflect.ValueOf(v), "", "")
+	case o.format == "env":
+		return usageError("env output is only available for a single secret")
+	}
+	C.printRecent(list)
+	return nil
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// printTemplate executes the template on the JSON form of v, so that templates use the field names of the schema
+func (C *cli) printTemplate(text string, v interface{}) error {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return usageError("invalid template: %v", err)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return err
+	}
+	if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err = C.stdout.Write(out.Bytes())
+	return err
+}
+
+// printYAML writes the struct v as a YAML mapping with the keys and order of its JSON form, its first line prefixed by
+// first and the others by indent
+func printYAML(w io.Writer, v reflect.Value, first, indent string) error {
+	prefix := first
+	for i := 0; i < v.NumField(); i++ {
+		name, omitempty := jsonName(v.Type().Field(i))
+		field := v.Field(i)
+		if omitempty && field.IsZero() {
+			continue
+		}
+
+		var err error
+		switch {
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct:
+			if field.Len() == 0 {
+				_, err = fmt.Fprintf(w, "%s%s: []\n", prefix, name)
+				break
+			}
+			_, err = fmt.Fprintf(w, "%s%s:\n", prefix, name)
+			for j := 0; j < field.Len() && err == nil; j++ {
+				if _, err = fmt.Fprintf(w, "%s  - ", indent); err == nil {
+					err = printYAML(w, field.Index(j), "", indent+"    ")
+				}
+			}
+		default:
+			var b []byte
+			if b, err = json.Marshal(field.Interface()); err == nil {
+				// JSON scalars and flow sequences are valid YAML
+				_, err = fmt.Fprintf(w, "%s%s: %s\n", prefix, name, b)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		prefix = indent
+	}
+	return nil
+}
+
+// printEnv writes v as shell variable assignments, OTS_ followed by the upper-cased JSON key, that can be sourced or
+// passed to eval
+func printEnv(w io.Writer, v secretView) error {
+	rv := reflect.ValueOf(v)
+	for i := 0; i < rv.NumField(); i++ {
+		name, omitempty := jsonName(rv.Type().Field(i))
+		field := rv.Field(i)
+		if omitempty && field.IsZero() {
+			continue
+		}
+
+		var value string
+		switch f := field.Interface().(type) {
+		case string:
+			value = f
+		case []string:
+			value = strings.Join(f, ",")
+		case *time.Time:
+			if f != nil {
+				value = f.Format(time.RFC3339)
+			}
+		default:
+			value = fmt.Sprint(f)
+		}
+
+		if _, err := fmt.Fprintf(w, "OTS_%s=%s\n", strings.ToUpper(name), shellQuote(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonName returns the JSON key of a struct field and whether it is omitted when empty
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	name := tag
+	if i := strings.Index(tag, ","); i >= 0 {
+		name = tag[:i]
+	}
+	return name, strings.Contains(tag, ",omitempty")
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
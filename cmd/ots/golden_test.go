@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// goldenStart is the time of the Clock of the golden tests
+var goldenStart = time.Unix(1700000000, 0)
+
+var (
+	keyPattern   = regexp.MustCompile(`[0-9a-f]{32}`)
+	valuePattern = regexp.MustCompile(`\b[0-9a-f]{12}\b`)
+)
+
+// normalize replaces what changes from one run to the next, the URL of the fake server and the keys and values it
+// generates, with stable placeholders numbered in order of appearance
+func normalize(s, serverURL string) string {
+	s = strings.Replace(s, serverURL, "https://ots.test", -1)
+	for _, p := range []struct {
+		pattern *regexp.Regexp
+		prefix  string
+	}{{keyPattern, "KEY"}, {valuePattern, "VALUE"}} {
+		seen := map[string]string{}
+		s = p.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if _, ok := seen[match]; !ok {
+				seen[match] = p.prefix + "_" + strconv.Itoa(len(seen)+1)
+			}
+			return seen[match]
+		})
+	}
+	return s
+}
+
+// checkGolden compares got with the golden file testdata/golden/name, or rewrites the file with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run go test -update if the change is intended\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// goldenSecrets are the secrets that exist before a golden command runs: one shared with a recipient, one generated
+// and read by its recipient a minute later
+type goldenSecrets struct {
+	shared   *onetimesecret.CreateSecretResponse
+	received *onetimesecret.GenerateSecretResponse
+}
+
+func TestGolden(t *testing.T) {
+	_, cleanup := hermetic(t)
+	defer cleanup()
+	defer setenv(onetimesecret.EnvUsername, "alice")()
+	defer setenv(onetimesecret.EnvAPIToken, "token")()
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	outputs := map[string][]string{
+		"":         nil,
+		"json":     {"--output", "json"},
+		"yaml":     {"--output", "yaml"},
+		"table":    {"--output", "table"},
+		"env":      {"--output", "env"},
+		"template": {"--template", "{{.state}} {{.share_link}} {{len .recipients}}"},
+	}
+	tests := []struct {
+		name    string
+		stdin   string
+		args    func(s goldenSecrets) []string
+		outputs bool
+	}{
+		{
+			name:  "share",
+			stdin: "s3cret\n",
+			args: func(goldenSecrets) []string {
+				return []string{"share", "--ttl", "1h", "--recipient", "bob@example.com"}
+			},
+			outputs: true,
+		},
+		{
+			name:    "generate",
+			args:    func(goldenSecrets) []string { return []string{"generate", "--ttl", "1h"} },
+			outputs: true,
+		},
+		{
+			name:    "meta",
+			args:    func(s goldenSecrets) []string { return []string{"meta", s.shared.MetadataKey} },
+			outputs: true,
+		},
+		{
+			name:    "meta-received",
+			args:    func(s goldenSecrets) []string { return []string{"meta", s.received.PrivateLink()} },
+			outputs: true,
+		},
+		{
+			name:    "burn",
+			args:    func(s goldenSecrets) []string { return []string{"burn", s.shared.MetadataKey} },
+			outputs: true,
+		},
+		{
+			name:    "recent",
+			args:    func(goldenSecrets) []string { return []string{"recent"} },
+			outputs: true,
+		},
+		{
+			name: "get",
+			args: func(s goldenSecrets) []string { return []string{"get", s.shared.ShareLink()} },
+		},
+		{
+			name: "get-received",
+			args: func(s goldenSecrets) []string { return []string{"get", s.received.SecretKey} },
+		},
+		{
+			name: "status",
+			args: func(goldenSecrets) []string { return []string{"status"} },
+		},
+		{
+			name: "share-unknown-output",
+			args: func(goldenSecrets) []string { return []string{"share", "--output", "xml"} },
+		},
+	}
+	for _, tt := range tests {
+		formats := []string{""}
+		if tt.outputs {
+			formats = []string{"", "json", "yaml", "table", "env", "template"}
+		}
+		for _, format := range formats {
+			name := tt.name
+			if format != "" {
+				name += "-" + format
+			}
+			t.Run(name, func(t *testing.T) {
+				clock := otstest.NewClock(goldenStart)
+				srv := otstest.NewServer(&otstest.ServerOptions{Now: clock.Now, Accounts: map[string]string{"alice": "token"}})
+				defer srv.Close()
+				defer setenv(envURL, srv.URL)()
+
+				setup := srv.Client(t, &onetimesecret.ClientOptions{Credentials: &onetimesecret.Credentials{Username: "alice", APIToken: "token"}})
+				var s goldenSecrets
+				var err error
+				if s.shared, err = setup.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", TTL: time.Hour, Recipient: []string{"bob@example.com"}}); err != nil {
+					t.Fatal(err)
+				}
+				clock.Advance(time.Second)
+				if s.received, err = setup.GenerateSecret(&onetimesecret.GenerateSecretRequest{Passphrase: "pw"}); err != nil {
+					t.Fatal(err)
+				}
+				clock.Advance(time.Minute)
+				if _, err := setup.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: s.received.SecretKey, Passphrase: "pw"}); err != nil {
+					t.Fatal(err)
+				}
+				clock.Advance(time.Minute)
+
+				// the flags go before the arguments of the command
+				args := tt.args(s)
+				args = append(append(append([]string{}, args[0]), outputs[format]...), args[1:]...)
+				code, stdout, stderr := runCLI(tt.stdin, args...)
+				got := fmt.Sprintf("$ ots %s\nexit status %d\n-- stdout --\n%s-- stderr --\n%s", strings.Join(args, " "), code, stdout, stderr)
+				checkGolden(t, name+".golden", normalize(got, srv.URL))
+			})
+		}
+	}
+}
+
+// jsonSchema describes the JSON form of a struct as its keys and their types
+func jsonSchema(t reflect.Type) map[string]string {
+	schema := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		name, omitempty := jsonName(t.Field(i))
+		typ := jsonType(t.Field(i).Type)
+		if omitempty {
+			typ += ", omitted when empty"
+		}
+		schema[name] = typ
+	}
+	return schema
+}
+
+// jsonType names the JSON type of a Go type of the schema
+func jsonType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(&time.Time{}):
+		return "RFC 3339 string or null"
+	case t == reflect.TypeOf(secretView{}):
+		return "secret"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Slice:
+		return "array of " + jsonType(t.Elem())
+	}
+	return t.String()
+}
+
+// TestJSONSchema keeps the JSON schema of --output json, yaml and env and of --template in step with
+// testdata/schema/v<schemaVersion>.json. Adding a field updates the file of the current version with -update; removing
+// or changing one needs a new schemaVersion.
+func TestJSONSchema(t *testing.T) {
+	got := map[string]map[string]string{
+		"secret": jsonSchema(reflect.TypeOf(secretView{})),
+		"list":   jsonSchema(reflect.TypeOf(secretListView{})),
+	}
+	path := filepath.Join("testdata", "schema", fmt.Sprintf("v%d.json", schemaVersion))
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !(*update && os.IsNotExist(err)) {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	want := map[string]map[string]string{}
+	if err == nil {
+		if err := json.Unmarshal(b, &want); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+
+	var changed []string
+	for object, fields := range want {
+		for name, typ := range fields {
+			if got[object][name] != typ {
+				changed = append(changed, fmt.Sprintf("%s.%s was %q, is %q", object, name, typ, got[object][name]))
+			}
+		}
+	}
+	sort.Strings(changed)
+	if len(changed) > 0 {
+		t.Fatalf("fields of schema version %d were removed or changed, increase schemaVersion:\n%s", schemaVersion, strings.Join(changed, "\n"))
+	}
+
+	if *update {
+		b, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields were added to schema version %d, run go test -update to add them to %s", schemaVersion, path)
+	}
+}
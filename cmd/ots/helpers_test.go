@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setenv sets an environment variable for the rest of a test, returning a func that restores it
+func setenv(key, value string) func() {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+// hermetic points the config directory and the home directory of ots at a new temporary directory and clears every
+// OTS_ variable, so that a test sees neither the user's config file nor their credentials. It returns the directory
+// and a func that restores the environment and removes the directory.
+func hermetic(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "ots")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var restore []func()
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "OTS_") {
+			key := kv[:strings.Index(kv, "=")]
+			restore = append(restore, setenv(key, ""))
+			os.Unsetenv(key)
+		}
+	}
+	// os.UserConfigDir reads XDG_CONFIG_HOME or HOME on Unix, AppData on Windows
+	for _, key := range []string{"XDG_CONFIG_HOME", "HOME", "AppData", "USERPROFILE"} {
+		restore = append(restore, setenv(key, dir))
+	}
+
+	return dir, func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// runCLI runs ots with the arguments and stdin, returning its exit status, stdout and stderr
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	c := &cli{ctx: context.Background(), stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	code := c.run(args)
+	return code, stdout.String(), stderr.String()
+}
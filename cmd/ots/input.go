@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// isTerminal reports whether r is an interactive terminal
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// trimNewline removes a single trailing newline, as left by echo or an editor, from s
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// readSecret reads a secret from the file at path, or from stdin if path is "" or "-"
+func (C *cli) readSecret(path string) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	if path != "" && path != "-" {
+		b, err = ioutil.ReadFile(path)
+	} else {
+		if isTerminal(C.stdin) {
+			fmt.Fprintln(C.stderr, "Enter the secret, then press Ctrl-D on a new line:")
+		}
+		b, err = ioutil.ReadAll(C.stdin)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	secret := trimNewline(string(b))
+	if secret == "" {
+		return "", fmt.Errorf("the secret is empty")
+	}
+	return secret, nil
+}
+
+// prompt asks for a line of input on stderr, hiding what is typed if masked and stdin is a terminal
+//
+// The line is read from in, which must wrap the stdin of C, so that successive prompts share its buffer.
+func (C *cli) prompt(in *bufio.Reader, label string, masked bool) (string, error) {
+	fmt.Fprint(C.stderr, label)
+
+	if f, ok := C.stdin.(*os.File); ok && masked && isTerminal(f) {
+		if err := setEcho(f, false); err != nil {
+			return "", fmt.Errorf("hiding input: %v", err)
+		}
+		defer func() {
+			setEcho(f, true)
+			fmt.Fprintln(C.stderr)
+		}()
+	}
+
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := in.ReadString('\n')
+		done <- result{line, err}
+	}()
+
+	// an interrupt must not leave the terminal without echo, so the read is abandoned when the context ends
+	select {
+	case r := <-done:
+		line := strings.TrimRight(r.line, "\r\n")
+		if r.err != nil && (r.err != io.EOF || line == "") {
+			if r.err == io.EOF {
+				return "", fmt.Errorf("no input")
+			}
+			return "", r.err
+		}
+		return line, nil
+	case <-C.ctx.Done():
+		return "", C.ctx.Err()
+	}
+}
+
+// readPassphrase reads a passphrase from the file at path, returning "" if path is ""
+func readPassphrase(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	passphrase := trimNewline(string(b))
+	if passphrase == "" {
+		return "", fmt.Errorf("the passphrase in %s is empty", path)
+	}
+	return passphrase, nil
+}
+
+// linkNames are the names users know the kinds of links by
+var linkNames = map[onetimesecret.LinkKind]string{
+	onetimesecret.LinkSecret:  "share",
+	onetimesecret.LinkPrivate: "private",
+}
+
+// parseTarget reads a link of the given kind or a bare key, returning the base URL of the link ("" for a bare key)
+// and the key
+func parseTarget(arg string, kind onetimesecret.LinkKind) (string, string, error) {
+	if !strings.Contains(arg, "/") {
+		if arg == "" {
+			return "", "", usageError("the key must not be empty")
+		}
+		return "", arg, nil
+	}
+
+	link, err := onetimesecret.ParseLink(arg)
+	if err != nil {
+		return "", "", err
+	}
+	if link.Kind != kind {
+		return "", "", usageError("%s is a %s link, a %s link is needed", arg, linkNames[link.Kind], linkNames[kind])
+	}
+	return link.BaseURL, link.Key, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+func runLogin(c *cli, args []string) error {
+	var (
+		g          globalFlags
+		apiVersion string
+	)
+
+	fs := c.flagSet("login", "", "Prompt for a username and API token, verify them against the service and save them to\na profile of the config file. --url, --region and --api-version are saved with them; the\nservice of an existing profile is kept unless they are given.")
+	g.register(fs)
+	fs.StringVar(&apiVersion, "api-version", "", "`version` of the service API: v1 or v2 (default v1)")
+	if err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	path, err := onetimesecret.DefaultConfigFile()
+	if err != nil {
+		return err
+	}
+	profile, err := onetimesecret.LoadProfile(path, g.profile)
+	switch {
+	case errors.Is(err, onetimesecret.ErrProfileNotFound):
+		profile = &onetimesecret.Profile{Name: onetimesecret.ProfileName(g.profile)}
+	case err != nil:
+		return err
+	}
+
+	if g.url != "" || g.region != "" {
+		profile.URL = g.url
+		profile.Region = onetimesecret.Region(g.region)
+	}
+	if apiVersion != "" {
+		profile.APIVersion = onetimesecret.APIVersion(apiVersion)
+	}
+
+	in := bufio.NewReader(c.stdin)
+	if profile.Username, err = c.prompt(in, "Username: ", false); err != nil {
+		return err
+	}
+	if profile.APIToken, err = c.prompt(in, "API token: ", true); err != nil {
+		return err
+	}
+	if profile.Username == "" || profile.APIToken == "" {
+		return fmt.Errorf("the username and API token must not be empty")
+	}
+
+	client, err := onetimesecret.NewWithOptions(profile.ClientOptions())
+	if err != nil {
+		return err
+	}
+	resp, err := client.AuthcheckWithContext(c.ctx)
+	if errors.Is(err, onetimesecret.ErrUnauthorized) {
+		return fmt.Errorf("the service rejected the username and API token, nothing was saved")
+	}
+	if err != nil {
+		return fmt.Errorf("verifying the credentials: %v", err)
+	}
+
+	if err := onetimesecret.SaveProfile(path, profile); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "Logged in as %s, saved to profile %q of %s\n", resp.CustID, profile.Name, path)
+	return nil
+}
@@ -0,0 +1,131 @@
+// Command ots shares secrets through https://onetimesecret.com from the command line
+//
+// Secrets and passphrases are read from stdin or files, never from the command line, so that they stay out of shell
+// history and process listings. Run "ots help" for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// command is a subcommand of ots
+type command struct {
+	name    string
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "share", summary: "share a secret read from stdin or a file", run: runShare},
+		{name: "generate", summary: "generate a random secret and share it", run: runGenerate},
+		{name: "get", summary: "retrieve a secret from its share link or secret key", run: runGet},
+		{name: "exec", summary: "run a command with retrieved secrets in its environment", run: runExec},
+		{name: "meta", summary: "show the metadata of a secret from its private link or metadata key", run: runMeta},
+		{name: "burn", summary: "destroy a secret before it is received", run: runBurn},
+		{name: "watch", summary: "wait until a secret is received, burned or expired", run: runWatch},
+		{name: "recent", summary: "list the recent secrets of your account", run: runRecent},
+		{name: "bulk", summary: "create the secrets of a CSV or YAML manifest", run: runBulk},
+		{name: "status", summary: "check that the service is up", run: runStatus},
+		{name: "login", summary: "verify credentials and save them to a profile of the config file", run: runLogin},
+	}
+}
+
+// exitError makes ots exit with a particular code, printing its error if it has one
+type exitError struct {
+	code int
+	err  error
+}
+
+func (E *exitError) Error() string {
+	if E.err == nil {
+		return fmt.Sprintf("exit status %d", E.code)
+	}
+	return E.err.Error()
+}
+
+// usageError makes ots exit with status 2 after printing err
+func usageError(format string, args ...interface{}) error {
+	return &exitError{code: 2, err: fmt.Errorf(format, args...)}
+}
+
+// cli holds the standard streams and the context of a run of ots
+type cli struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	c := &cli{ctx: ctx, stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run runs the command named by the first argument and returns the exit status of ots
+func (C *cli) run(args []string) int {
+	if len(args) == 0 {
+		C.usage()
+		return 2
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		C.usage()
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		err := cmd.run(C, args[1:])
+		var exit *exitError
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.As(err, &exit):
+			if exit.err != nil {
+				fmt.Fprintf(C.stderr, "ots %s: %v\n", cmd.name, exit.err)
+			}
+			return exit.code
+		default:
+			fmt.Fprintf(C.stderr, "ots %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(C.stderr, "ots: unknown command %q\n\n", args[0])
+	C.usage()
+	return 2
+}
+
+func (C *cli) usage() {
+	fmt.Fprintf(C.stderr, "Usage: ots <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(C.stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(C.stderr, "\nRun \"ots <command> -h\" for the flags of a command.\n")
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manifestRow is a row of a bulk manifest, by column name
+type manifestRow map[string]string
+
+// manifestFormat returns the format of a manifest, csv or yaml, from the extension of its path
+func manifestFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	}
+	return "", usageError("%s: a manifest must be a .csv, .yaml or .yml file", path)
+}
+
+// readManifest reads the rows of the CSV or YAML manifest at path
+func readManifest(path string) ([]manifestRow, error) {
+	format, err := manifestFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []manifestRow
+	if format == "csv" {
+		rows, err = parseCSV(b)
+	} else {
+		rows, err = parseYAML(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return rows, nil
+}
+
+// writeManifest replaces the manifest at path with rows, writing the given columns in order and leaving it readable
+// by its owner only
+//
+// The file is replaced atomically, so that it is never left half written.
+func writeManifest(path string, columns []string, rows []manifestRow) error {
+	format, err := manifestFormat(path)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if format == "csv" {
+		w := csv.NewWriter(&buf)
+		w.Write(columns)
+		for _, row := range rows {
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = row[column]
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	} else {
+		writeYAML(&buf, columns, rows)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// parseCSV reads a CSV manifest, whose first record names the columns
+func parseCSV(b []byte) ([]manifestRow, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	rows := make([]manifestRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(manifestRow, len(header))
+		for i, value := range record {
+			row[header[i]] = strings.TrimSpace(value)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseYAML reads a YAML manifest
+//
+// Only the subset of YAML a manifest needs is supported: a sequence of mappings from keys to scalars, which may be
+// plain, single quoted or double quoted, and comments. For example:
+//
+//     - recipient: alice@example.com
+//       generate: true
+//       ttl: 72h
+//     - recipient: bob@example.com  # a comment
+//       secret: "s3cr3t"
+//       passphrase: generate
+func parseYAML(b []byte) ([]manifestRow, error) {
+	var (
+		rows    []manifestRow
+		row     manifestRow
+		indent  = -1
+		scanner = bufio.NewScanner(bytes.NewReader(b))
+	)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content[0] == '#' || content == "---" {
+			continue
+		}
+		if content == "[]" && rows == nil {
+			continue
+		}
+
+		col := len(line) - len(content)
+		switch {
+		case content == "-" || strings.HasPrefix(content, "- "):
+			if indent >= 0 && col != indent-2 {
+				return nil, fmt.Errorf("line %d: unexpected indentation", n)
+			}
+			row = manifestRow{}
+			rows = append(rows, row)
+			content = strings.TrimLeft(content[1:], " ")
+			indent = len(line) - len(content)
+			if content == "" {
+				// the first key is on the next line
+				indent = -1
+				continue
+			}
+		case row == nil:
+			return nil, fmt.Errorf("line %d: a manifest must be a list of secrets, each starting with \"- \"", n)
+		case indent < 0:
+			indent = col
+		case col != indent:
+			return nil, fmt.Errorf("line %d: unexpected indentation", n)
+		}
+
+		i := strings.Index(content, ":")
+		if i <= 0 || (i+1 < len(content) && content[i+1] != ' ') {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", n)
+		}
+		key := strings.ToLower(strings.TrimSpace(content[:i]))
+		value, err := parseYAMLScalar(strings.TrimSpace(content[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if _, ok := row[key]; ok {
+			return nil, fmt.Errorf("line %d: %s is set more than once", n, key)
+		}
+		row[key] = value
+	}
+	return rows, scanner.Err()
+}
+
+// parseYAMLScalar reads a plain, single quoted or double quoted YAML scalar, followed by an optional comment
+func parseYAMLScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				var value string
+				if err := json.Unmarshal([]byte(s[:i+1]), &value); err != nil {
+					return "", fmt.Errorf("invalid double quoted string %s", s[:i+1])
+				}
+				return value, checkYAMLRest(s[i+1:])
+			}
+		}
+		return "", fmt.Errorf("unterminated double quoted string")
+	case strings.HasPrefix(s, "'"):
+		var value strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				value.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				value.WriteByte('\'')
+				i++
+				continue
+			}
+			return value.String(), checkYAMLRest(s[i+1:])
+		}
+		return "", fmt.Errorf("unterminated single quoted string")
+	}
+
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if s != "" && strings.ContainsAny(s[:1], "[{|>&*!%@`") {
+		return "", fmt.Errorf("unsupported value %s, only plain and quoted strings are", s)
+	}
+	return s, nil
+}
+
+// checkYAMLRest checks that only a comment follows a quoted scalar
+func checkYAMLRest(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && rest[0] != '#' {
+		return fmt.Errorf("unexpected %s after a quoted string", rest)
+	}
+	return nil
+}
+
+// writeYAML writes rows as a YAML sequence of mappings, with their columns in order and every value double quoted.
+// Empty values are left out, so every row must have at least one value.
+func writeYAML(buf *bytes.Buffer, columns []string, rows []manifestRow) {
+	if len(rows) == 0 {
+		buf.WriteString("[]\n")
+		return
+	}
+	for _, row := range rows {
+		prefix := "- "
+		for _, column := range columns {
+			value, ok := row[column]
+			if !ok || value == "" {
+				continue
+			}
+			// a JSON string is a valid YAML double quoted string
+			b, _ := json.Marshal(value)
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, column, b)
+			prefix = "  "
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestParseYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "", want: ""},
+		{in: "plain", want: "plain"},
+		{in: "alice@example.com", want: "alice@example.com"},
+		{in: "72h  # a comment", want: "72h"},
+		{in: "a#b", want: "a#b"},
+		{in: "value:s3cr3t", want: "value:s3cr3t"},
+		{in: `"double"`, want: "double"},
+		{in: `"with # inside" # and a comment`, want: "with # inside"},
+		{in: `"esc\"aped\\ \té"`, want: "esc\"aped\\ \té"},
+		{in: `"ünïcödé"`, want: "ünïcödé"},
+		{in: `'single'`, want: "single"},
+		{in: `'it''s # not a comment' # but this is`, want: "it's # not a comment"},
+		{in: `'back\slash'`, want: `back\slash`},
+		{in: `"unterminated`, wantErr: "unterminated double quoted string"},
+		{in: `"ends with \"`, wantErr: "unterminated double quoted string"},
+		{in: `'unterminated`, wantErr: "unterminated single quoted string"},
+		{in: `"bad \q escape"`, wantErr: "invalid double quoted string"},
+		{in: `"quoted" trailing`, wantErr: "unexpected trailing after a quoted string"},
+		{in: `'quoted' trailing`, wantErr: "unexpected trailing after a quoted string"},
+		{in: "[a, b]", wantErr: "unsupported value"},
+		{in: "{a: b}", wantErr: "unsupported value"},
+		{in: "|", wantErr: "unsupported value"},
+		{in: "&anchor", wantErr: "unsupported value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseYAMLScalar(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseYAMLScalar(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseYAMLScalar(%q) error = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseYAMLScalar(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []manifestRow
+		wantErr string
+	}{
+		{
+			name: "list of mappings",
+			in: "# secrets of the team\n---\n- id: alice\n  recipient: alice@example.com  # a comment\n\n  generate: true\n" +
+				"- id: bob\n  secret: \"s3cr3t # not a comment\"\n  passphrase: 'value:it''s'\n",
+			want: []manifestRow{
+				{"id": "alice", "recipient": "alice@example.com", "generate": "true"},
+				{"id": "bob", "secret": "s3cr3t # not a comment", "passphrase": "value:it's"},
+			},
+		},
+		{
+			name: "dash on its own line",
+			in:   "-\n  id: alice\n  generate: true\n-\n    id: bob\n    secret: x\n",
+			want: []manifestRow{
+				{"id": "alice", "generate": "true"},
+				{"id": "bob", "secret": "x"},
+			},
+		},
+		{
+			name: "indented list",
+			in:   "  - id: alice\n    secret: x\n  - id: bob\n    secret: y\n",
+			want: []manifestRow{{"id": "alice", "secret": "x"}, {"id": "bob", "secret": "y"}},
+		},
+		{
+			name: "keys are case insensitive",
+			in:   "- ID: alice\n  Secret: x\n",
+			want: []manifestRow{{"id": "alice", "secret": "x"}},
+		},
+		{name: "empty list", in: "[]\n", want: nil},
+		{name: "empty file", in: "", want: nil},
+		{name: "CRLF line endings", in: "- id: alice\r\n  secret: x\r\n", want: []manifestRow{{"id": "alice", "secret": "x"}}},
+		{name: "key deeper than the first", in: "- id: alice\n   secret: x\n", wantErr: "line 2: unexpected indentation"},
+		{name: "key shallower than the first", in: "- id: alice\n secret: x\n", wantErr: "line 2: unexpected indentation"},
+		{name: "item at another indentation", in: "- id: alice\n  secret: x\n  - id: bob\n", wantErr: "line 3: unexpected indentation"},
+		{name: "mapping instead of a list", in: "id: alice\n", wantErr: "line 1: a manifest must be a list of secrets"},
+		{name: "missing colon", in: "- id alice\n", wantErr: "line 1: expected \"key: value\""},
+		{name: "missing space after colon", in: "- id:alice\n", wantErr: "line 1: expected \"key: value\""},
+		{name: "empty key", in: "- : alice\n", wantErr: "line 1: expected \"key: value\""},
+		{name: "duplicate key", in: "- id: alice\n  secret: x\n  ID: bob\n", wantErr: "line 3: id is set more than once"},
+		{name: "bad scalar", in: "- id: alice\n  secret: \"x\n", wantErr: "line 2: unterminated double quoted string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.in))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseYAML() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseYAML() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	columns := []string{"id", "secret", "passphrase", "error"}
+	rows := []manifestRow{
+		{"id": "plain", "secret": "s3cr3t"},
+		{"id": "punctuation", "secret": `a,b "c" 'd' #e: f - g`, "passphrase": "value:p#ss"},
+		{"id": "unicode", "secret": "ünïcödé ✓"},
+		{"id": "multi-line", "secret": "line 1\nline 2\ttabbed"},
+		{"id": "yaml-like", "secret": "- [x]: {y} | > & * ! % @ `", "error": "Post \"https://example.com\": failed"},
+	}
+
+	for _, name := range []string{"results.csv", "results.yaml", "results.yml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := writeManifest(path, columns, rows); err != nil {
+				t.Fatal(err)
+			}
+			if runtime.GOOS != "windows" {
+				if fi, err := os.Stat(path); err != nil {
+					t.Fatal(err)
+				} else if mode := fi.Mode().Perm(); mode != 0600 {
+					t.Errorf("mode = %v, want 0600", mode)
+				}
+			}
+
+			got, err := readManifest(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(rows) {
+				t.Fatalf("%d rows read back, want %d", len(got), len(rows))
+			}
+			for i := range rows {
+				for _, column := range columns {
+					if got[i][column] != rows[i][column] {
+						t.Errorf("row %d: %s = %q, want %q", i+1, column, got[i][column], rows[i][column])
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		path := filepath.Join(dir, "empty.yaml")
+		if err := writeManifest(path, columns, nil); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readManifest(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("%d rows read back, want 0", len(got))
+		}
+	})
+}
+
+func TestBulkResume(t *testing.T) {
+	for _, writeValues := range []bool{false, true} {
+		t.Run(fmt.Sprintf("write values %v", writeValues), func(t *testing.T) {
+			dir, cleanup := hermetic(t)
+			defer cleanup()
+			defer setenv(onetimesecret.EnvUsername, "alice")()
+			defer setenv(onetimesecret.EnvAPIToken, "token")()
+			srv := otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{"alice": "token"}})
+			defer srv.Close()
+
+			manifest := filepath.Join(dir, "team.csv")
+			content := "id,recipient,secret,generate\nalice,alice@example.com,s3cr3t,\nbob,bob@example.com,,true\ncarol,,s3cr3t,\n"
+			if err := ioutil.WriteFile(manifest, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			// alice was created by the previous run, bob failed and carol was never reached
+			results := filepath.Join(dir, "team.results.csv")
+			previous := []manifestRow{
+				{"id": "alice", "recipient": "alice@example.com", "metadata_key": "previousmetadatakey", "share_link": "https://example.com/secret/previous"},
+				{"id": "bob", "recipient": "bob@example.com", "error": "service returned a non-200 status code: 500"},
+			}
+			if err := writeManifest(results, resultColumns, previous); err != nil {
+				t.Fatal(err)
+			}
+
+			args := []string{"bulk", "--url", srv.URL, "--resume"}
+			if writeValues {
+				args = append(args, "--write-values")
+			}
+			code, _, stderr := runCLI("", append(args, manifest)...)
+			if code != 0 {
+				t.Fatalf("exit status = %d, want 0\n%s", code, stderr)
+			}
+			if !strings.Contains(stderr, "Created 2 secrets, 0 failed, 1 already created") {
+				t.Errorf("unexpected summary:\n%s", stderr)
+			}
+
+			// only carol was shared and only bob generated, alice was not created again
+			if n := srv.Requests("CreateSecret"); n != 1 {
+				t.Errorf("%d secrets shared, want 1", n)
+			}
+			if n := srv.Requests("GenerateSecret"); n != 1 {
+				t.Errorf("%d secrets generated, want 1", n)
+			}
+
+			b, err := ioutil.ReadFile(results)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header := strings.SplitN(string(b), "\n", 2)[0]; strings.Contains(header, "value") != writeValues {
+				t.Errorf("header = %q, want a value column: %v", header, writeValues)
+			}
+			if runtime.GOOS != "windows" {
+				if fi, err := os.Stat(results); err != nil {
+					t.Fatal(err)
+				} else if mode := fi.Mode().Perm(); mode != 0600 {
+					t.Errorf("mode = %v, want 0600", mode)
+				}
+			}
+
+			rows, err := readManifest(results)
+			if err != nil {
+				t.Fatal(err)
+			}
+			byID := make(map[string]manifestRow)
+			for _, row := range rows {
+				byID[row["id"]] = row
+			}
+			if got := byID["alice"]; got["metadata_key"] != "previousmetadatakey" || got["share_link"] != "https://example.com/secret/previous" {
+				t.Errorf("alice = %v, want the row of the previous run", got)
+			}
+			if got := byID["bob"]; got["metadata_key"] == "" || (got["value"] != "") != writeValues || got["error"] != "" {
+				t.Errorf("bob = %v, want a generated secret, with its value: %v", got, writeValues)
+			}
+			if got := byID["carol"]; got["metadata_key"] == "" || got["value"] != "" || got["error"] != "" {
+				t.Errorf("carol = %v, want a shared secret", got)
+			}
+		})
+	}
+}
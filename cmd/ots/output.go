@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// printCreated prints the share link of a new secret to stdout and its private link to stderr, so that the share
+// link can be piped on while the private link stays with the creator
+func (C *cli) printCreated(m *onetimesecret.Metadata) {
+	fmt.Fprintln(C.stdout, m.ShareLink())
+	fmt.Fprintf(C.stderr, "Private link (do not share): %s\n", m.PrivateLink())
+}
+
+// printMetadata prints the metadata of a secret as a two column table
+func (C *cli) printMetadata(m *onetimesecret.Metadata) {
+	w := tabwriter.NewWriter(C.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Metadata key\t%s\n", m.MetadataKey)
+	fmt.Fprintf(w, "State\t%s\n", m.State)
+	fmt.Fprintf(w, "Created\t%s\n", formatTime(m.CreatedAt))
+	fmt.Fprintf(w, "Expires\t%s\n", formatTime(expiresAt(m)))
+	fmt.Fprintf(w, "Received\t%s\n", formatTime(m.Received))
+	fmt.Fprintf(w, "Recipient\t%s\n", orDash(strings.Join(m.Recipient, ", ")))
+	fmt.Fprintf(w, "Passphrase\t%s\n", yesNo(m.PassphraseRequired))
+	fmt.Fprintf(w, "Share link\t%s\n", orDash(m.ShareLink()))
+	fmt.Fprintf(w, "Private link\t%s\n", orDash(m.PrivateLink()))
+	w.Flush()
+}
+
+// printRecent prints a list of metadata as a table, one secret per row
+func (C *cli) printRecent(list []onetimesecret.Metadata) {
+	w := tabwriter.NewWriter(C.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METADATA KEY\tSTATE\tCREATED\tEXPIRES\tRECIPIENT")
+	for i := range list {
+		m := &list[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.MetadataKey, m.State, formatTime(m.CreatedAt), formatTime(expiresAt(m)),
+			orDash(strings.Join(m.Recipient, ", ")))
+	}
+	w.Flush()
+}
+
+// expiresAt returns when the secret expires, or the zero time.Time if it is already gone
+func expiresAt(m *onetimesecret.Metadata) time.Time {
+	if m.State.Final() {
+		return time.Time{}
+	}
+	return m.ExpiresAt()
+}
+
+// formatTime formats t in the local time zone, and the zero time.Time as "-"
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05 MST")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
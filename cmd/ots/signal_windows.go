@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package main
+
+import "os"
+
+// forwardSignals are the signals ots exec passes on to the command it runs. There are none on Windows, where Ctrl-C
+// already reaches every process attached to the console.
+var forwardSignals []os.Signal
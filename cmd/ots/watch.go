@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+// The exit codes of ots watch for each outcome, besides 0 for a received secret, 1 for an error and 2 for a usage
+// error
+const (
+	exitBurned   = 3
+	exitExpired  = 4
+	exitTimedOut = 5
+)
+
+func runWatch(c *cli, args []string) error {
+	var (
+		g       globalFlags
+		o       outputFlags
+		opts    onetimesecret.WatchOptions
+		timeout time.Duration
+	)
+
+	fs := c.flagSet("watch", "<private-link|metadata-key>", "Wait until a secret is received, burned or expired. It exits with status 0 when the\nsecret is received, 3 when it is burned, 4 when it expires, 5 when --timeout runs out and\n1 on errors.")
+	g.register(fs)
+	o.register(fs)
+	fs.DurationVar(&opts.Interval, "interval", onetimesecret.DefaultWatchInterval, "delay before the second poll, doubling after every poll up to --max-interval")
+	fs.DurationVar(&opts.MaxInterval, "max-interval", onetimesecret.DefaultWatchMaxInterval, "longest delay between two polls")
+	fs.DurationVar(&timeout, "timeout", 0, "give up after `duration` (default: wait until the secret is gone)")
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	baseURL, key, err := parseTarget(fs.Arg(0), onetimesecret.LinkPrivate)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(&g, baseURL)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var event *onetimesecret.WatchEvent
+	for e := range onetimesecret.NewWatcher(client, &opts).Watch(ctx, key) {
+		if e.Kind == onetimesecret.EventError && !e.Final {
+			fmt.Fprintf(c.stderr, "ots watch: %v, retrying\n", e.Err)
+			continue
+		}
+		e := e
+		event = &e
+	}
+
+	switch {
+	case event == nil && c.ctx.Err() != nil:
+		return c.ctx.Err()
+	case event == nil:
+		return &exitError{code: exitTimedOut, err: fmt.Errorf("the secret was not gone after %v", timeout)}
+	case event.Kind == onetimesecret.EventError:
+		return event.Err
+	}
+
+	ok, err := c.printSecret(&o, event.Metadata, "")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Fprintln(c.stdout, event.Kind)
+	}
+
+	switch event.Kind {
+	case onetimesecret.EventBurned:
+		return &exitError{code: exitBurned}
+	case onetimesecret.EventExpired:
+		return &exitError{code: exitExpired}
+	}
+	return nil
+}
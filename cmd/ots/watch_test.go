@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestWatchExitCodes(t *testing.T) {
+	_, cleanup := hermetic(t)
+	defer cleanup()
+	defer setenv(onetimesecret.EnvUsername, "alice")()
+	defer setenv(onetimesecret.EnvAPIToken, "token")()
+
+	tests := []struct {
+		name       string
+		change     func(t *testing.T, client *onetimesecret.Client, clock *otstest.Clock, secret *onetimesecret.CreateSecretResponse)
+		args       []string
+		key        string
+		noKey      bool
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name: "received",
+			change: func(t *testing.T, client *onetimesecret.Client, _ *otstest.Clock, secret *onetimesecret.CreateSecretResponse) {
+				if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: secret.SecretKey}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantCode:   0,
+			wantStdout: "received\n",
+		},
+		{
+			name: "burned",
+			change: func(t *testing.T, client *onetimesecret.Client, _ *otstest.Clock, secret *onetimesecret.CreateSecretResponse) {
+				if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: secret.MetadataKey}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantCode:   exitBurned,
+			wantStdout: "burned\n",
+		},
+		{
+			name: "expired",
+			change: func(_ *testing.T, _ *onetimesecret.Client, clock *otstest.Clock, _ *onetimesecret.CreateSecretResponse) {
+				clock.Advance(time.Hour)
+			},
+			wantCode:   exitExpired,
+			wantStdout: "expired\n",
+		},
+		{
+			name:       "timed out",
+			args:       []string{"--timeout", "50ms"},
+			wantCode:   exitTimedOut,
+			wantStderr: "ots watch: the secret was not gone after 50ms\n",
+		},
+		{
+			name:       "unknown secret",
+			key:        "0123456789abcdef0123456789abcdef",
+			wantCode:   1,
+			wantStderr: "Unknown secret",
+		},
+		{
+			name:       "usage",
+			args:       []string{"--interval"},
+			noKey:      true,
+			wantCode:   2,
+			wantStderr: "Usage: ots watch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := otstest.NewClock(time.Unix(1700000000, 0))
+			srv := otstest.NewServer(&otstest.ServerOptions{Now: clock.Now, Accounts: map[string]string{"alice": "token"}})
+			defer srv.Close()
+
+			client := srv.Client(t, &onetimesecret.ClientOptions{Credentials: &onetimesecret.Credentials{Username: "alice", APIToken: "token"}})
+			secret, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", TTL: time.Hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the secret changes before ots watch runs
+			if tt.change != nil {
+				tt.change(t, client, clock, secret)
+			}
+			key := tt.key
+			if key == "" {
+				key = secret.MetadataKey
+			}
+
+			args := append([]string{"watch", "--url", srv.URL, "--interval", "5ms", "--max-interval", "10ms"}, tt.args...)
+			if !tt.noKey {
+				args = append(args, key)
+			}
+			code, stdout, stderr := runCLI("", args...)
+			if code != tt.wantCode {
+				t.Errorf("exit status = %d, want %d, stderr: %s", code, tt.wantCode, stderr)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout, tt.wantStdout)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) || (tt.wantStderr == "" && stderr != "") {
+				t.Errorf("stderr = %q, want %q", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestWatchInterrupted(t *testing.T) {
+	_, cleanup := hermetic(t)
+	defer cleanup()
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	secret, err := srv.Client(t, nil).CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var stdout, stderr bytes.Buffer
+	c := &cli{ctx: ctx, stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+	if code := c.run([]string{"watch", "--url", srv.URL, secret.MetadataKey}); code != 1 {
+		t.Errorf("exit status = %d, want 1", code)
+	}
+	if want := "ots watch: context canceled\n"; stdout.String() != "" || stderr.String() != want {
+		t.Errorf("stdout, stderr = %q, %q, want nothing and %q", stdout.String(), stderr.String(), want)
+	}
+}
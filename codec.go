@@ -0,0 +1,82 @@
+package onetimesecret
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIVersion selects the version of the service API that a Client speaks
+type APIVersion string
+
+const (
+	// APIVersion1 is the legacy /api/v1 API with form encoded requests, spoken by https://onetimesecret.com and
+	// the default when no APIVersion is set
+	APIVersion1 APIVersion = "v1"
+
+	// APIVersion2 is the /api/v2 JSON API with record/details response envelopes, spoken by newer deployments
+	APIVersion2 APIVersion = "v2"
+)
+
+// codec translates operations to and from the wire format of one version of the service API
+//
+// Operations are always described in their v1 form and responses are always handed to the response types as v1
+// JSON, so the Client methods and response types do not depend on the API version.
+type codec interface {
+	// route returns the HTTP method and the endpoint path, with keyParam standing in for the key, of an operation
+	route(name string) (method, path string)
+
+	// encode returns the request body of an operation and its content type, or a nil body if it has none
+	encode(op *operation) ([]byte, string, error)
+
+	// decode converts the body of a 200 response to an operation into v1 JSON
+	decode(op *operation, b []byte) ([]byte, error)
+}
+
+// newCodec returns the codec for an API version, an empty version meaning APIVersion1
+func newCodec(version APIVersion) (codec, error) {
+	switch version {
+	case "", APIVersion1:
+		return v1Codec{}, nil
+	case APIVersion2:
+		return v2Codec{}, nil
+	}
+	return nil, fmt.Errorf("unsupported API version %q", string(version))
+}
+
+// route is the HTTP method and endpoint path template of an operation
+type route struct {
+	method string
+	path   string
+}
+
+// v1Routes maps operation names to their /api/v1 endpoints
+var v1Routes = map[string]route{
+	"CreateSecret":           {http.MethodPost, "/api/v1/share"},
+	"GenerateSecret":         {http.MethodPost, "/api/v1/generate"},
+	"RetrieveSecret":         {http.MethodPost, "/api/v1/secret/" + keyParam},
+	"RetrieveMetadata":       {http.MethodPost, "/api/v1/private/" + keyParam},
+	"BurnSecret":             {http.MethodPost, "/api/v1/private/" + keyParam + "/burn"},
+	"RetrieveRecentMetadata": {http.MethodGet, "/api/v1/private/recent"},
+	"Status":                 {http.MethodGet, "/api/v1/status"},
+	"Authcheck":              {http.MethodGet, "/api/v1/authcheck"},
+}
+
+// v1Codec speaks the /api/v1 API, which takes application/x-www-form-urlencoded bodies and answers in plain JSON
+type v1Codec struct{}
+
+func (v1Codec) route(name string) (string, string) {
+	r := v1Routes[name]
+	return r.method, r.path
+}
+
+func (v1Codec) encode(op *operation) ([]byte, string, error) {
+	if op.params == nil {
+		return nil, "", nil
+	}
+	return []byte(op.params.Encode()), "application/x-www-form-urlencoded", nil
+}
+
+func (v1Codec) decode(op *operation, b []byte) ([]byte, error) {
+	return b, nil
+}
+
@@ -0,0 +1,312 @@
+package onetimesecret_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// apiVersions maps the API versions to the endpoint of their RetrieveMetadata call
+var apiVersions = map[onetimesecret.APIVersion]string{
+	onetimesecret.APIVersion1: "POST /api/v1/private/{key}",
+	onetimesecret.APIVersion2: "GET /api/v2/private/{key}",
+}
+
+// sentRequest is a request as a bodyRecorder saw it
+type sentRequest struct {
+	method      string
+	path        string
+	contentType string
+	body        string
+}
+
+// bodyRecorder is an http.RoundTripper that records the method, path and body of the requests it sends
+type bodyRecorder struct {
+	mu        sync.Mutex
+	transport http.RoundTripper
+	requests  []sentRequest
+}
+
+func (B *bodyRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		body = b
+	}
+	B.mu.Lock()
+	B.requests = append(B.requests, sentRequest{req.Method, req.URL.Path, req.Header.Get("Content-Type"), string(body)})
+	B.mu.Unlock()
+	return B.transport.RoundTrip(req)
+}
+
+// TestAPIVersions runs the same calls through each API version and expects the same results
+func TestAPIVersions(t *testing.T) {
+	for version, metadataEndpoint := range apiVersions {
+		t.Run(string(version), func(t *testing.T) {
+			clock := otstest.NewClock(time.Unix(1700000000, 0))
+			srv := otstest.NewServer(&otstest.ServerOptions{Now: clock.Now, Accounts: map[string]string{testUsername: testAPIToken}})
+			defer srv.Close()
+			client := newTestClient(t, srv, &onetimesecret.ClientOptions{APIVersion: version})
+
+			created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{
+				Secret:     "s3cret",
+				Passphrase: "pw",
+				TTL:        time.Hour,
+				Recipient:  []string{"bob@example.com"},
+			})
+			if err != nil {
+				t.Fatalf("CreateSecret: %v", err)
+			}
+			if created.MetadataKey == "" || created.SecretKey == "" || created.CustID != testUsername {
+				t.Errorf("CreateSecret = %+v, want both keys and the custid %q", created, testUsername)
+			}
+			if created.SecretTTL != time.Hour || created.TTL != time.Hour || !created.PassphraseRequired {
+				t.Errorf("SecretTTL, TTL, PassphraseRequired = %v, %v, %v, want 1h, 1h, true", created.SecretTTL, created.TTL, created.PassphraseRequired)
+			}
+			if want := []string{"b*****@example.com"}; !reflect.DeepEqual(created.Recipient, want) {
+				t.Errorf("Recipient = %q, want %q", created.Recipient, want)
+			}
+			if created.State != onetimesecret.StateNew {
+				t.Errorf("State = %v, want %v", created.State, onetimesecret.StateNew)
+			}
+
+			generated, err := client.GenerateSecret(&onetimesecret.GenerateSecretRequest{})
+			if err != nil {
+				t.Fatalf("GenerateSecret: %v", err)
+			}
+			if generated.Value == "" || generated.MetadataKey == "" || generated.SecretKey == "" || generated.PassphraseRequired {
+				t.Errorf("GenerateSecret = %+v, want a value, both keys and no passphrase", generated)
+			}
+
+			// the error envelope
+			_, err = client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey})
+			if !errors.Is(err, onetimesecret.ErrPassphraseRequired) {
+				t.Errorf("RetrieveSecret without the passphrase: err = %v, want ErrPassphraseRequired", err)
+			}
+			var apiErr *onetimesecret.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message == "" {
+				t.Errorf("err = %#v, want an *APIError with a status of 404 and a message", err)
+			}
+
+			retrieved, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey, Passphrase: "pw"})
+			if err != nil {
+				t.Fatalf("RetrieveSecret: %v", err)
+			}
+			if retrieved.SecretKey != created.SecretKey || retrieved.SecretValue != "s3cret" {
+				t.Errorf("RetrieveSecret = %+v, want %s and s3cret", retrieved, created.SecretKey)
+			}
+
+			metadata, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey})
+			if err != nil {
+				t.Fatalf("RetrieveMetadata: %v", err)
+			}
+			if metadata.MetadataKey != created.MetadataKey || metadata.State != onetimesecret.StateReceived || metadata.Received.IsZero() {
+				t.Errorf("RetrieveMetadata = %+v, want the received metadata of %s", metadata, created.MetadataKey)
+			}
+
+			burned, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: generated.MetadataKey})
+			if err != nil {
+				t.Fatalf("BurnSecret: %v", err)
+			}
+			if burned.MetadataKey != generated.MetadataKey || burned.State != onetimesecret.StateBurned {
+				t.Errorf("BurnSecret = %+v, want the burned metadata of %s", burned, generated.MetadataKey)
+			}
+
+			recent, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+			if err != nil {
+				t.Fatalf("RetrieveRecentMetadata: %v", err)
+			}
+			keys := map[string]bool{}
+			for _, m := range *recent {
+				keys[m.MetadataKey] = true
+			}
+			if len(*recent) != 2 || !keys[created.MetadataKey] || !keys[generated.MetadataKey] {
+				t.Errorf("RetrieveRecentMetadata = %+v, want %s and %s", recent, created.MetadataKey, generated.MetadataKey)
+			}
+
+			_, err = client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: "0123456789abcdef0123456789abcdef"})
+			if !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+				t.Errorf("RetrieveMetadata of an unknown key: err = %v, want ErrSecretNotFound", err)
+			}
+			if !errors.As(err, &apiErr) || apiErr.Endpoint != metadataEndpoint {
+				t.Errorf("err = %#v, want the Endpoint %q", err, metadataEndpoint)
+			}
+
+			if status, err := client.Status(); err != nil || status.Status != "nominal" {
+				t.Errorf("Status = %+v, %v, want nominal", status, err)
+			}
+			if auth, err := client.Authcheck(); err != nil || auth.CustID != testUsername {
+				t.Errorf("Authcheck = %+v, %v, want the custid %q", auth, err, testUsername)
+			}
+		})
+	}
+}
+
+func TestV2Requests(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	srv := newTestServer()
+	defer srv.Close()
+	rec := &bodyRecorder{transport: srv.HTTPClient().Transport}
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{
+		APIVersion: onetimesecret.APIVersion2,
+		HTTPClient: &http.Client{Transport: rec},
+	})
+
+	tests := []struct {
+		name   string
+		call   func() error
+		method string
+		path   string
+		body   string
+	}{
+		{
+			name: "CreateSecret",
+			call: func() error {
+				_, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", Passphrase: "pw", TTL: time.Hour, Recipient: []string{"bob@example.com"}})
+				return err
+			},
+			method: http.MethodPost,
+			path:   "/api/v2/secret/conceal",
+			body:   `{"secret": {"secret": "s3cret", "passphrase": "pw", "ttl": 3600, "recipient": ["bob@example.com"]}}`,
+		},
+		{
+			name: "GenerateSecret",
+			call: func() error {
+				_, err := client.GenerateSecret(&onetimesecret.GenerateSecretRequest{TTL: time.Minute})
+				return err
+			},
+			method: http.MethodPost,
+			path:   "/api/v2/secret/generate",
+			body:   `{"secret": {"ttl": 60}}`,
+		},
+		{
+			name: "RetrieveSecret",
+			call: func() error {
+				_, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: key, Passphrase: "pw"})
+				return err
+			},
+			method: http.MethodPost,
+			path:   "/api/v2/secret/" + key + "/reveal",
+			body:   `{"passphrase": "pw", "continue": true}`,
+		},
+		{
+			name: "RetrieveMetadata",
+			call: func() error {
+				_, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: key})
+				return err
+			},
+			method: http.MethodGet,
+			path:   "/api/v2/private/" + key,
+		},
+		{
+			name: "BurnSecret",
+			call: func() error {
+				_, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: key})
+				return err
+			},
+			method: http.MethodPost,
+			path:   "/api/v2/private/" + key + "/burn",
+			body:   `{"continue": true}`,
+		},
+		{
+			name: "RetrieveRecentMetadata",
+			call: func() error {
+				_, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+				return err
+			},
+			method: http.MethodGet,
+			path:   "/api/v2/private/recent",
+		},
+		{
+			name:   "Status",
+			call:   func() error { _, err := client.Status(); return err },
+			method: http.MethodGet,
+			path:   "/api/v2/status",
+		},
+		{
+			name:   "Authcheck",
+			call:   func() error { _, err := client.Authcheck(); return err },
+			method: http.MethodGet,
+			path:   "/api/v2/authcheck",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.requests = nil
+			// the unknown key makes some of the calls fail, only the request matters here
+			tt.call()
+			if len(rec.requests) != 1 {
+				t.Fatalf("%d requests sent, want 1", len(rec.requests))
+			}
+			got := rec.requests[0]
+			if got.method != tt.method || got.path != tt.path {
+				t.Errorf("request = %s %s, want %s %s", got.method, got.path, tt.method, tt.path)
+			}
+			if tt.body == "" {
+				if got.body != "" {
+					t.Errorf("body = %s, want none", got.body)
+				}
+				return
+			}
+			if got.contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got.contentType)
+			}
+			var gotBody, wantBody interface{}
+			if err := json.Unmarshal([]byte(got.body), &gotBody); err != nil {
+				t.Fatalf("body %s: %v", got.body, err)
+			}
+			if err := json.Unmarshal([]byte(tt.body), &wantBody); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotBody, wantBody) {
+				t.Errorf("body = %s, want %s", got.body, tt.body)
+			}
+		})
+	}
+}
+
+func TestNewWithOptionsAPIVersion(t *testing.T) {
+	tests := []struct {
+		version onetimesecret.APIVersion
+		wantErr bool
+	}{
+		{"", false},
+		{onetimesecret.APIVersion1, false},
+		{onetimesecret.APIVersion2, false},
+		{"v3", true},
+		{"V2", true},
+		{"2", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.version), func(t *testing.T) {
+			client, err := onetimesecret.NewWithOptions(&onetimesecret.ClientOptions{
+				OneTimeSecretURL: "https://onetimesecret.com",
+				APIVersion:       tt.version,
+				HTTPClient:       http.DefaultClient,
+			})
+			if tt.wantErr {
+				if err == nil || client != nil {
+					t.Errorf("NewWithOptions(APIVersion: %q) = %v, %v, want an error", tt.version, client, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("NewWithOptions(APIVersion: %q): %v", tt.version, err)
+			}
+		})
+	}
+}
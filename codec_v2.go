@@ -0,0 +1,163 @@
+package onetimesecret
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// v2Routes maps operation names to their /api/v2 endpoints
+var v2Routes = map[string]route{
+	"CreateSecret":           {http.MethodPost, "/api/v2/secret/conceal"},
+	"GenerateSecret":         {http.MethodPost, "/api/v2/secret/generate"},
+	"RetrieveSecret":         {http.MethodPost, "/api/v2/secret/" + keyParam + "/reveal"},
+	"RetrieveMetadata":       {http.MethodGet, "/api/v2/private/" + keyParam},
+	"BurnSecret":             {http.MethodPost, "/api/v2/private/" + keyParam + "/burn"},
+	"RetrieveRecentMetadata": {http.MethodGet, "/api/v2/private/recent"},
+	"Status":                 {http.MethodGet, "/api/v2/status"},
+	"Authcheck":              {http.MethodGet, "/api/v2/authcheck"},
+}
+
+// v2Codec speaks the /api/v2 API, which takes JSON bodies and answers with a record (or records) and details envelope
+//
+//  Requests
+//
+//    CreateSecret: {"secret": {"secret": ..., "passphrase": ..., "ttl": ..., "recipient": [...]}}
+//    GenerateSecret: {"secret": {"passphrase": ..., "ttl": ..., "recipient": [...]}}
+//    RetrieveSecret: {"passphrase": ..., "continue": true}
+//    BurnSecret: {"continue": true}
+//
+//  Responses
+//
+//    CreateSecret, GenerateSecret: {"record": {"metadata": {...}, "secret": {...}}, "details": {...}}
+//    RetrieveSecret: {"record": {"secret_value": ...}, "details": {...}}
+//    RetrieveMetadata, BurnSecret: {"record": {...}, "details": {...}}
+//    RetrieveRecentMetadata: {"records": [...], "details": {...}}
+//    Status: {"status": ..., "locale": ...}, the same as v1
+//    Authcheck: {"record": {...}, "details": {...}}
+//
+// Records name their own key "key" (or "identifier") and are otherwise mapped onto the v1 field names.
+type v2Codec struct{}
+
+// v2Envelope is the envelope of every /api/v2 response
+type v2Envelope struct {
+	Record  map[string]interface{}   `json:"record"`
+	Records []map[string]interface{} `json:"records"`
+	Details map[string]interface{}   `json:"details"`
+}
+
+func (v2Codec) route(name string) (string, string) {
+	r := v2Routes[name]
+	return r.method, r.path
+}
+
+func (v2Codec) encode(op *operation) ([]byte, string, error) {
+	var body interface{}
+
+	switch op.name {
+	case "CreateSecret", "GenerateSecret":
+		secret := map[string]interface{}{}
+		if op.name == "CreateSecret" {
+			secret["secret"] = op.params.Get("secret")
+		}
+		if v := op.params.Get("passphrase"); v != "" {
+			secret["passphrase"] = v
+		}
+		if v := op.params.Get("ttl"); v != "" {
+			ttl, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, "", err
+			}
+			secret["ttl"] = ttl
+		}
+		if recipients := op.params["recipient"]; len(recipients) != 0 {
+			secret["recipient"] = recipients
+		}
+		body = map[string]interface{}{"secret": secret}
+	case "RetrieveSecret":
+		reveal := map[string]interface{}{"continue": true}
+		if v := op.params.Get("passphrase"); v != "" {
+			reveal["passphrase"] = v
+		}
+		body = reveal
+	case "BurnSecret":
+		body = map[string]interface{}{"continue": true}
+	default:
+		return nil, "", nil
+	}
+
+	b, err := json.Marshal(body)
+	return b, "application/json", err
+}
+
+func (v2Codec) decode(op *operation, b []byte) ([]byte, error) {
+	if op.name == "Status" {
+		return b, nil
+	}
+
+	var env v2Envelope
+	if err := json.Unmarshal(b, &env); err != nil {
+		return nil, err
+	}
+
+	var v1 interface{}
+
+	switch op.name {
+	case "CreateSecret", "GenerateSecret":
+		metadata, _ := env.Record["metadata"].(map[string]interface{})
+		secret, _ := env.Record["secret"].(map[string]interface{})
+		m := v2Metadata(metadata, env.Details)
+		setFirst(m, "secret_key", secret["key"], secret["identifier"], metadata["secret_key"])
+		setFirst(m, "secret_ttl", secret["secret_ttl"], metadata["secret_ttl"])
+		setFirst(m, "passphrase_required", secret["has_passphrase"], env.Details["has_passphrase"], metadata["passphrase_required"])
+		setFirst(m, "value", env.Record["secret_value"], secret["value"], env.Details["secret_value"])
+		v1 = m
+	case "RetrieveSecret":
+		m := map[string]interface{}{}
+		setFirst(m, "secret_key", env.Record["secret_key"], env.Record["key"], env.Record["identifier"], op.key)
+		setFirst(m, "value", env.Record["secret_value"], env.Record["value"])
+		v1 = m
+	case "RetrieveRecentMetadata":
+		list := make([]map[string]interface{}, 0, len(env.Records))
+		for _, record := range env.Records {
+			list = append(list, v2Metadata(record, nil))
+		}
+		v1 = list
+	case "Authcheck":
+		m := map[string]interface{}{}
+		for k, v := range env.Record {
+			m[k] = v
+		}
+		setFirst(m, "custid", env.Record["custid"], env.Record["identifier"])
+		v1 = m
+	default:
+		v1 = v2Metadata(env.Record, env.Details)
+	}
+
+	return json.Marshal(v1)
+}
+
+// v2Metadata maps a v2 metadata record onto the v1 metadata fields
+func v2Metadata(record, details map[string]interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	for k, v := range record {
+		m[k] = v
+	}
+	setFirst(m, "metadata_key", record["metadata_key"], record["key"], record["identifier"])
+	setFirst(m, "recipient", record["recipient"], record["recipients"], details["recipient"], details["recipients"])
+	if recipient, ok := m["recipient"].(string); ok {
+		m["recipient"] = []string{recipient}
+	}
+	return m
+}
+
+// setFirst sets m[key] to the first of values that is neither nil nor an empty string, leaving m untouched if there is none
+func setFirst(m map[string]interface{}, key string, values ...interface{}) {
+	for _, v := range values {
+		if s, ok := v.(string); v == nil || ok && s == "" {
+			continue
+		}
+		m[key] = v
+		return
+	}
+}
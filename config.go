@@ -0,0 +1,318 @@
+package onetimesecret
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// EnvProfile is the environment variable that selects the profile of the config file, "default" if it is unset
+	EnvProfile = "OTS_PROFILE"
+
+	// DefaultProfile is the profile of the config file used when no other is selected
+	DefaultProfile = "default"
+)
+
+// ErrProfileNotFound is returned by LoadProfile when the config file or the profile does not exist
+var ErrProfileNotFound = errors.New("profile not found")
+
+// profileKeys are the keys of a profile that Profile reads and SaveProfile writes, in the order they are written
+var profileKeys = []string{"url", "region", "api_version", "username", "api_token"}
+
+// DefaultConfigFile will return the path of the config file shared by FileProvider and the ots command
+//
+// The file lives in the user's config directory, $XDG_CONFIG_HOME/onetimesecret/config (usually
+// ~/.config/onetimesecret/config) on Linux. It holds named profiles in an INI-like format:
+//
+//    [default]
+//    username = jordan@example.com
+//    api_token = abcdefg1234567
+//
+//    [work]
+//    username = jordan@example.org
+//    api_token = hijklmn8901234
+//
+// Blank lines and lines starting with "#" or ";" are ignored.
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (string): The path of the config file
+//     (error):  An error if the user's config directory can not be determined, nil otherwise
+func DefaultConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "onetimesecret", "config"), nil
+}
+
+// ProfileName will resolve the name of the profile to use
+//
+// Variables:
+//     name (string): The name of the profile, "" to select it with EnvProfile
+//
+// Returns:
+//     (string): name, or the profile named by EnvProfile if name is "", or DefaultProfile if that is unset too
+func ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if name = os.Getenv(EnvProfile); name != "" {
+		return name
+	}
+	return DefaultProfile
+}
+
+// parseConfig reads the profiles of a config file, mapping each profile name to its keys and values
+func parseConfig(r io.Reader) (map[string]map[string]string, error) {
+	var (
+		profiles = map[string]map[string]string{}
+		current  map[string]string
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if profiles[name] == nil {
+				profiles[name] = map[string]string{}
+			}
+			current = profiles[name]
+		default:
+			i := strings.Index(line, "=")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: expected key = value", n)
+			}
+			if current == nil {
+				return nil, fmt.Errorf("line %d: key outside of a [profile] section", n)
+			}
+			current[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	return profiles, scanner.Err()
+}
+
+// Profile is a named profile of the config file, holding the service and credentials to use
+//
+//  Attributes
+//
+//    Name: the name of the profile, e.g. "default".
+//    URL: the base URL of the service, under the "url" key.
+//    Region: the hosted region of the service instead of a URL, under the "region" key.
+//    APIVersion: the version of the service API, under the "api_version" key.
+//    Username: the username of the account, under the "username" key.
+//    APIToken: the API token of the account, under the "api_token" key.
+type Profile struct {
+	Name       string
+	URL        string
+	Region     Region
+	APIVersion APIVersion
+	Username   string
+	APIToken   string
+}
+
+// LoadProfile will read a profile of the config file
+//
+// Variables:
+//     path (string): The path of the config file, DefaultConfigFile() if ""
+//     name (string): The name of the profile, resolved with ProfileName
+//
+// Returns:
+//     (*Profile): A pointer to the Profile, nil if an error occurred
+//     (error):    An error wrapping ErrProfileNotFound if the file or the profile does not exist, another error if the
+//                 file can not be read, nil otherwise
+func LoadProfile(path, name string) (*Profile, error) {
+	path, err := configPath(path)
+	if err != nil {
+		return nil, err
+	}
+	name = ProfileName(name)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrProfileNotFound, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles, err := parseConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	keys, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: no [%s] in %s", ErrProfileNotFound, name, path)
+	}
+	return &Profile{
+		Name:       name,
+		URL:        keys["url"],
+		Region:     Region(keys["region"]),
+		APIVersion: APIVersion(keys["api_version"]),
+		Username:   keys["username"],
+		APIToken:   keys["api_token"],
+	}, nil
+}
+
+// ClientOptions will build the options of a Client that uses the service and credentials of the profile
+//
+// The Client talks to DefaultURL if the profile has neither a URL nor a Region, and is anonymous if the profile has
+// no credentials.
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*ClientOptions): A pointer to the options, with a new HTTP client
+func (P *Profile) ClientOptions() *ClientOptions {
+	opts := &ClientOptions{
+		OneTimeSecretURL: P.URL,
+		Region:           P.Region,
+		APIVersion:       P.APIVersion,
+		HTTPClient:       &http.Client{},
+	}
+	if opts.OneTimeSecretURL == "" && opts.Region == "" {
+		opts.OneTimeSecretURL = DefaultURL
+	}
+	if P.Username != "" && P.APIToken != "" {
+		opts.Credentials = &Credentials{Username: P.Username, APIToken: P.APIToken}
+	}
+	return opts
+}
+
+// values returns the value of every one of the profileKeys, "" for those that are not set
+func (P *Profile) values() map[string]string {
+	return map[string]string{
+		"url":         P.URL,
+		"region":      string(P.Region),
+		"api_version": string(P.APIVersion),
+		"username":    P.Username,
+		"api_token":   P.APIToken,
+	}
+}
+
+// SaveProfile will write a profile to the config file, replacing the profile of the same name
+//
+// Other profiles, comments and unknown keys are kept. The file and its directory are created if they do not exist,
+// readable only by their owner as the file holds API tokens.
+//
+// Variables:
+//     path (string):      The path of the config file, DefaultConfigFile() if ""
+//     profile (*Profile): A pointer to the Profile to write. Its Name is resolved with ProfileName.
+//
+// Returns:
+//     (error): An error if the file can not be read or written, nil otherwise
+func SaveProfile(path string, profile *Profile) error {
+	path, err := configPath(path)
+	if err != nil {
+		return err
+	}
+	name := ProfileName(profile.Name)
+
+	old, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := parseConfig(bytes.NewReader(old)); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	var section []string
+	values := profile.values()
+	for _, key := range profileKeys {
+		if values[key] != "" {
+			section = append(section, key+" = "+values[key])
+		}
+	}
+
+	var (
+		lines   []string
+		inside  bool
+		written bool
+	)
+	for _, line := range strings.Split(strings.TrimRight(string(old), "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			inside = strings.TrimSpace(trimmed[1:len(trimmed)-1]) == name
+			if inside && !written {
+				lines = append(lines, line)
+				lines = append(lines, section...)
+				written = true
+				continue
+			}
+		}
+		if inside && isProfileKey(trimmed) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if !written {
+		if len(lines) > 0 && lines[len(lines)-1] != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+name+"]")
+		lines = append(lines, section...)
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".config-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// isProfileKey reports whether a line of the config file sets one of the profileKeys
+func isProfileKey(line string) bool {
+	i := strings.Index(line, "=")
+	if i < 0 {
+		return false
+	}
+	key := strings.TrimSpace(line[:i])
+	for _, k := range profileKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// configPath returns path, or DefaultConfigFile() if path is ""
+func configPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return DefaultConfigFile()
+}
@@ -0,0 +1,248 @@
+package onetimesecret_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+func TestDefaultConfigFile(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "plan9" {
+		t.Skip("the XDG base directories are only used on Unix")
+	}
+	defer setenv("HOME", "/home/alice")()
+
+	tests := []struct {
+		name string
+		xdg  string
+		want string
+	}{
+		{name: "XDG_CONFIG_HOME", xdg: "/tmp/xdg", want: "/tmp/xdg/onetimesecret/config"},
+		{name: "HOME without XDG_CONFIG_HOME", want: "/home/alice/.config/onetimesecret/config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.xdg != "" {
+				defer setenv("XDG_CONFIG_HOME", tt.xdg)()
+			} else {
+				defer unsetenv("XDG_CONFIG_HOME")()
+			}
+			got, err := onetimesecret.DefaultConfigFile()
+			if err != nil || got != tt.want {
+				t.Errorf("DefaultConfigFile() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path, cleanup := writeConfig(t, `# profiles of the tests
+; another comment
+
+[default]
+url = https://secrets.example.org/
+api_version = v2
+username = alice
+api_token = default-token
+
+[ work ]
+region = eu
+  username =  alice@example.org
+api_token = tok=en
+unknown = kept
+
+[empty]
+`)
+	defer cleanup()
+
+	tests := []struct {
+		name       string
+		profile    string
+		envProfile string
+		want       *onetimesecret.Profile
+	}{
+		{
+			name: "default",
+			want: &onetimesecret.Profile{Name: "default", URL: "https://secrets.example.org/", APIVersion: onetimesecret.APIVersion2, Username: "alice", APIToken: "default-token"},
+		},
+		{
+			name:    "named",
+			profile: "work",
+			want:    &onetimesecret.Profile{Name: "work", Region: onetimesecret.RegionEU, Username: "alice@example.org", APIToken: "tok=en"},
+		},
+		{
+			name:       "OTS_PROFILE",
+			envProfile: "work",
+			want:       &onetimesecret.Profile{Name: "work", Region: onetimesecret.RegionEU, Username: "alice@example.org", APIToken: "tok=en"},
+		},
+		{
+			name:       "named over OTS_PROFILE",
+			profile:    "default",
+			envProfile: "work",
+			want:       &onetimesecret.Profile{Name: "default", URL: "https://secrets.example.org/", APIVersion: onetimesecret.APIVersion2, Username: "alice", APIToken: "default-token"},
+		},
+		{
+			name:    "empty",
+			profile: "empty",
+			want:    &onetimesecret.Profile{Name: "empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envProfile != "" {
+				defer setenv(onetimesecret.EnvProfile, tt.envProfile)()
+			} else {
+				defer unsetenv(onetimesecret.EnvProfile)()
+			}
+			got, err := onetimesecret.LoadProfile(path, tt.profile)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadProfile(%q) = %+v, %v, want %+v", tt.profile, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		profile  string
+		notFound bool
+		wantErr  string
+	}{
+		{name: "missing profile", content: "[default]\n", profile: "work", notFound: true, wantErr: "no [work] in"},
+		{name: "key outside of a section", content: "# comment\nusername = alice\n", wantErr: "line 2: key outside of a [profile] section"},
+		{name: "line without a value", content: "[default]\n\nusername\n", wantErr: "line 3: expected key = value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup := writeConfig(t, tt.content)
+			defer cleanup()
+			_, err := onetimesecret.LoadProfile(path, tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || errors.Is(err, onetimesecret.ErrProfileNotFound) != tt.notFound {
+				t.Errorf("LoadProfile() error = %v, want %q (ErrProfileNotFound: %v)", err, tt.wantErr, tt.notFound)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		path, cleanup := writeConfig(t, "")
+		defer cleanup()
+		if _, err := onetimesecret.LoadProfile(path+".missing", ""); !errors.Is(err, onetimesecret.ErrProfileNotFound) {
+			t.Errorf("LoadProfile() error = %v, want ErrProfileNotFound", err)
+		}
+	})
+}
+
+func TestSaveProfile(t *testing.T) {
+	path, cleanup := writeConfig(t, `# profiles of the tests
+[default]
+username = alice
+api_token = old-token
+# kept with the profile
+unknown = kept
+
+[other]
+username = bob
+api_token = bob-token
+`)
+	defer cleanup()
+	defer unsetenv(onetimesecret.EnvProfile)()
+
+	saved := []*onetimesecret.Profile{
+		{Name: "default", URL: "https://secrets.example.org", Username: "alice", APIToken: "new-token"},
+		{Name: "work", Region: onetimesecret.RegionEU, APIVersion: onetimesecret.APIVersion2, Username: "alice@example.org", APIToken: "work-token"},
+	}
+	for _, profile := range saved {
+		if err := onetimesecret.SaveProfile(path, profile); err != nil {
+			t.Fatalf("SaveProfile(%s): %v", profile.Name, err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# profiles of the tests
+[default]
+url = https://secrets.example.org
+username = alice
+api_token = new-token
+# kept with the profile
+unknown = kept
+
+[other]
+username = bob
+api_token = bob-token
+
+[work]
+region = eu
+api_version = v2
+username = alice@example.org
+api_token = work-token
+`
+	if string(b) != want {
+		t.Errorf("config file =\n%s\nwant\n%s", b, want)
+	}
+
+	for _, profile := range append(saved, &onetimesecret.Profile{Name: "other", Username: "bob", APIToken: "bob-token"}) {
+		got, err := onetimesecret.LoadProfile(path, profile.Name)
+		if err != nil || !reflect.DeepEqual(got, profile) {
+			t.Errorf("LoadProfile(%s) = %+v, %v, want %+v", profile.Name, got, err, profile)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("mode = %v, want -rw-------", mode)
+		}
+	}
+}
+
+func TestSaveProfileDefaultFile(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "plan9" {
+		t.Skip("the XDG base directories are only used on Unix")
+	}
+	dir, err := ioutil.TempDir("", "xdg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setenv("XDG_CONFIG_HOME", dir)()
+	defer setenv(onetimesecret.EnvProfile, "work")()
+
+	// the name and the path are resolved by SaveProfile and LoadProfile alike
+	profile := &onetimesecret.Profile{Username: "alice", APIToken: "token"}
+	if err := onetimesecret.SaveProfile("", profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	path := filepath.Join(dir, "onetimesecret", "config")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s was not written: %v", path, err)
+	}
+	if want := "[work]\nusername = alice\napi_token = token\n"; string(b) != want {
+		t.Errorf("config file = %q, want %q", b, want)
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("config directory = %v, %v, want mode drwx------", info, err)
+	}
+
+	got, err := onetimesecret.LoadProfile("", "")
+	want := &onetimesecret.Profile{Name: "work", Username: "alice", APIToken: "token"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadProfile() = %+v, %v, want %+v", got, err, want)
+	}
+}
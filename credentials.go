@@ -0,0 +1,222 @@
+package onetimesecret
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// EnvUsername is the environment variable EnvProvider reads the username from
+	EnvUsername = "OTS_USERNAME"
+
+	// EnvAPIToken is the environment variable EnvProvider reads the API token from
+	EnvAPIToken = "OTS_APITOKEN"
+)
+
+// ErrNoCredentials is returned by a CredentialsProvider that has no credentials to offer, letting ChainProvider move on
+// to the next provider
+var ErrNoCredentials = errors.New("no credentials found")
+
+// CredentialsProvider supplies the Credentials of a Client
+//
+// Retrieve is called for every request, so a provider can rotate tokens without the Client being rebuilt.
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (*Credentials, error)
+}
+
+// Retrieve will return the Credentials themselves, so that static Credentials can be used as a CredentialsProvider
+//
+// Variables:
+//     ctx (context.Context): Unused
+//
+// Returns:
+//     (*Credentials): The Credentials
+//     (error):        Always nil
+func (C *Credentials) Retrieve(ctx context.Context) (*Credentials, error) {
+	return C, nil
+}
+
+// EnvProvider supplies Credentials from the OTS_USERNAME and OTS_APITOKEN environment variables
+type EnvProvider struct{}
+
+// Retrieve will read the Credentials from the environment
+//
+// Variables:
+//     ctx (context.Context): Unused
+//
+// Returns:
+//     (*Credentials): A pointer to the Credentials, nil if an error occurred
+//     (error):        ErrNoCredentials if either variable is unset or empty, nil otherwise
+func (EnvProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	creds := &Credentials{
+		Username: os.Getenv(EnvUsername),
+		APIToken: os.Getenv(EnvAPIToken),
+	}
+	if creds.Username == "" || creds.APIToken == "" {
+		return nil, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+// FileProvider supplies Credentials from the username and api_token keys of a profile of a config file
+//
+// The file is read on every call, so edits take effect without the Client being rebuilt. See DefaultConfigFile for
+// the file format.
+//
+//  Attributes
+//
+//    Path: the path of the config file, DefaultConfigFile() if empty.
+//    Profile: the profile to read, the one named by OTS_PROFILE or "default" if empty.
+type FileProvider struct {
+	Path    string
+	Profile string
+}
+
+// Retrieve will read the Credentials from the config file
+//
+// Variables:
+//     ctx (context.Context): Unused
+//
+// Returns:
+//     (*Credentials): A pointer to the Credentials, nil if an error occurred
+//     (error):        ErrNoCredentials if the file or profile does not exist or lacks credentials, another error if
+//                     the file can not be read, nil otherwise
+func (F *FileProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	profile, err := LoadProfile(F.Path, F.Profile)
+	if errors.Is(err, ErrProfileNotFound) {
+		return nil, ErrNoCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Username == "" || profile.APIToken == "" {
+		return nil, ErrNoCredentials
+	}
+	return &Credentials{Username: profile.Username, APIToken: profile.APIToken}, nil
+}
+
+// ExecProvider supplies Credentials from the output of an external command, in the manner of git credential helpers
+//
+// The command must print "key=value" lines to stdout, with the username under "username" and the API token under
+// "api_token" (or "password"). Other lines are ignored. A command that exits with a non-zero status fails the
+// request, with its stderr in the error.
+//
+// The Credentials printed by the command are reused for TTL, so the command does not run for every request and every
+// retry. Failures are not cached.
+//
+//  Attributes
+//
+//    Command: the program to run, looked up in PATH if it contains no path separator.
+//    Args: the arguments passed to the program.
+//    TTL: how long the Credentials printed by the command are reused, DefaultExecTTL if zero. A negative TTL runs
+//         the command for every request.
+type ExecProvider struct {
+	Command string
+	Args    []string
+	TTL     time.Duration
+
+	mu      sync.Mutex
+	creds   *Credentials
+	expires time.Time
+}
+
+// DefaultExecTTL is how long an ExecProvider reuses the Credentials printed by its command when it has no TTL
+const DefaultExecTTL = 5 * time.Minute
+
+// Retrieve will return the cached Credentials, running the command and parsing the Credentials from its output once
+// they have expired
+//
+// Variables:
+//     ctx (context.Context): The context that bounds how long the command may run
+//
+// Returns:
+//     (*Credentials): A pointer to the Credentials, nil if an error occurred
+//     (error):        An error if the command fails or prints no credentials, nil otherwise
+func (E *ExecProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	E.mu.Lock()
+	defer E.mu.Unlock()
+
+	if E.creds != nil && time.Now().Before(E.expires) {
+		return E.creds, nil
+	}
+
+	creds, err := E.run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := E.TTL
+	if ttl == 0 {
+		ttl = DefaultExecTTL
+	}
+	E.creds = creds
+	E.expires = time.Now().Add(ttl)
+	return creds, nil
+}
+
+// run runs the command and parses the Credentials from its output
+func (E *ExecProvider) run(ctx context.Context) (*Credentials, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, E.Command, E.Args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential command %s: %v: %s", E.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	creds := new(Credentials)
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, "=")
+		if i < 0 {
+			continue
+		}
+		switch key, value := line[:i], line[i+1:]; key {
+		case "username":
+			creds.Username = value
+		case "api_token", "password":
+			creds.APIToken = value
+		}
+	}
+
+	if creds.Username == "" || creds.APIToken == "" {
+		return nil, fmt.Errorf("credential command %s: printed no username and api_token", E.Command)
+	}
+	return creds, nil
+}
+
+// ChainProvider supplies the Credentials of the first of its providers that has some
+//
+// Providers returning ErrNoCredentials are skipped; any other error stops the chain and is returned.
+type ChainProvider []CredentialsProvider
+
+// Retrieve will ask each provider in turn for Credentials
+//
+// Variables:
+//     ctx (context.Context): The context passed to every provider
+//
+// Returns:
+//     (*Credentials): A pointer to the first Credentials found, nil if an error occurred
+//     (error):        ErrNoCredentials if no provider had credentials, the first other error of a provider, nil otherwise
+func (P ChainProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	for _, provider := range P {
+		creds, err := provider.Retrieve(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return creds, err
+	}
+	return nil, ErrNoCredentials
+}
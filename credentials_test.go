@@ -0,0 +1,418 @@
+package onetimesecret_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// nilProvider is a CredentialsProvider that returns neither credentials nor an error
+type nilProvider struct{}
+
+func (nilProvider) Retrieve(ctx context.Context) (*onetimesecret.Credentials, error) {
+	return nil, nil
+}
+
+func TestNilCredentialsFromProvider(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client(t, &onetimesecret.ClientOptions{CredentialsProvider: nilProvider{}})
+
+	_, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if !errors.Is(err, onetimesecret.ErrNoCredentials) {
+		t.Errorf("err = %v, want ErrNoCredentials", err)
+	}
+	if n := srv.Requests("CreateSecret"); n != 0 {
+		t.Errorf("%d requests sent, want 0", n)
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		apiToken string
+		want     *onetimesecret.Credentials
+	}{
+		{"both set", "alice", "token", &onetimesecret.Credentials{Username: "alice", APIToken: "token"}},
+		{"no username", "", "token", nil},
+		{"no API token", "alice", "", nil},
+		{"neither", "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setenv(onetimesecret.EnvUsername, tt.username)()
+			defer setenv(onetimesecret.EnvAPIToken, tt.apiToken)()
+
+			creds, err := onetimesecret.EnvProvider{}.Retrieve(context.Background())
+			if tt.want == nil {
+				if !errors.Is(err, onetimesecret.ErrNoCredentials) {
+					t.Errorf("Retrieve() = %v, %v, want ErrNoCredentials", creds, err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(creds, tt.want) {
+				t.Errorf("Retrieve() = %v, %v, want %v", creds, err, tt.want)
+			}
+		})
+	}
+
+	defer unsetenv(onetimesecret.EnvUsername)()
+	defer unsetenv(onetimesecret.EnvAPIToken)()
+	if _, err := (onetimesecret.EnvProvider{}).Retrieve(context.Background()); !errors.Is(err, onetimesecret.ErrNoCredentials) {
+		t.Errorf("unset variables: err = %v, want ErrNoCredentials", err)
+	}
+}
+
+// writeConfig writes a config file into a new temporary directory, returning its path and a func that removes it
+func writeConfig(t *testing.T, content string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestFileProvider(t *testing.T) {
+	path, cleanup := writeConfig(t, `# profiles of the tests
+[default]
+username = alice
+api_token = default-token
+
+[work]
+username = alice@example.org
+api_token = work-token
+
+[incomplete]
+username = bob
+`)
+	defer cleanup()
+
+	tests := []struct {
+		name       string
+		path       string
+		profile    string
+		envProfile string
+		want       *onetimesecret.Credentials
+		wantErr    error
+	}{
+		{name: "default profile", path: path, want: &onetimesecret.Credentials{Username: "alice", APIToken: "default-token"}},
+		{name: "named profile", path: path, profile: "work", want: &onetimesecret.Credentials{Username: "alice@example.org", APIToken: "work-token"}},
+		{name: "profile from the environment", path: path, envProfile: "work", want: &onetimesecret.Credentials{Username: "alice@example.org", APIToken: "work-token"}},
+		{name: "named profile over the environment", path: path, profile: "default", envProfile: "work", want: &onetimesecret.Credentials{Username: "alice", APIToken: "default-token"}},
+		{name: "missing profile", path: path, profile: "personal", wantErr: onetimesecret.ErrNoCredentials},
+		{name: "profile without a token", path: path, profile: "incomplete", wantErr: onetimesecret.ErrNoCredentials},
+		{name: "missing file", path: path + ".missing", wantErr: onetimesecret.ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setenv(onetimesecret.EnvProfile, tt.envProfile)()
+
+			provider := &onetimesecret.FileProvider{Path: tt.path, Profile: tt.profile}
+			creds, err := provider.Retrieve(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Retrieve() = %v, %v, want %v", creds, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(creds, tt.want) {
+				t.Errorf("Retrieve() = %v, %v, want %v", creds, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("malformed file", func(t *testing.T) {
+		bad, cleanup := writeConfig(t, "username = alice\n")
+		defer cleanup()
+		_, err := (&onetimesecret.FileProvider{Path: bad}).Retrieve(context.Background())
+		if err == nil || errors.Is(err, onetimesecret.ErrNoCredentials) {
+			t.Errorf("err = %v, want an error other than ErrNoCredentials", err)
+		}
+	})
+}
+
+// TestHelperProcess is the credential command run by the ExecProvider tests. It is not a test of its own.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("OTS_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		os.Exit(2)
+	}
+
+	switch args[1] {
+	case "print":
+		fmt.Println("# a comment")
+		fmt.Println("protocol=https")
+		fmt.Println("username=alice")
+		fmt.Println("password=pass=word")
+	case "api_token":
+		fmt.Println("username=alice")
+		fmt.Println("api_token=token")
+		fmt.Println("password=ignored-as-api_token-comes-first")
+	case "count":
+		// appends a line to the file args[2] for every run
+		f, err := os.OpenFile(args[2], os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			os.Exit(3)
+		}
+		fmt.Fprintln(f, "run")
+		f.Close()
+		fmt.Println("username=alice")
+		fmt.Println("api_token=token")
+	case "fail":
+		fmt.Fprintln(os.Stderr, "vault is sealed")
+		os.Exit(1)
+	case "empty":
+		fmt.Println("nothing to see")
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// helperProvider returns an ExecProvider that runs TestHelperProcess in the given mode. Call it with the environment
+// set up by helperEnv.
+func helperProvider(args ...string) *onetimesecret.ExecProvider {
+	return &onetimesecret.ExecProvider{
+		Command: os.Args[0],
+		Args:    append([]string{"-test.run=^TestHelperProcess$", "--"}, args...),
+	}
+}
+
+// helperEnv sets up the environment of TestHelperProcess, returning a func that restores it
+func helperEnv() func() {
+	restoreHelper := setenv("OTS_TEST_HELPER_PROCESS", "1")
+	// the race detector otherwise sleeps for a second before the helper exits
+	restoreRace := setenv("GORACE", "atexit_sleep_ms=0")
+	return func() {
+		restoreRace()
+		restoreHelper()
+	}
+}
+
+func TestExecProvider(t *testing.T) {
+	defer helperEnv()()
+
+	tests := []struct {
+		name    string
+		mode    string
+		want    *onetimesecret.Credentials
+		wantErr string
+	}{
+		{name: "password", mode: "print", want: &onetimesecret.Credentials{Username: "alice", APIToken: "pass=word"}},
+		{name: "api_token", mode: "api_token", want: &onetimesecret.Credentials{Username: "alice", APIToken: "ignored-as-api_token-comes-first"}},
+		{name: "non-zero exit", mode: "fail", wantErr: "vault is sealed"},
+		{name: "no credentials", mode: "empty", wantErr: "printed no username and api_token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := helperProvider(tt.mode).Retrieve(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Retrieve() = %v, %v, want an error containing %q", creds, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(creds, tt.want) {
+				t.Errorf("Retrieve() = %v, %v, want %v", creds, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := helperProvider("hang").Retrieve(ctx); err == nil {
+			t.Error("Retrieve() of a command that outlives its context succeeded")
+		}
+		if d := time.Since(start); d > 10*time.Second {
+			t.Errorf("Retrieve() returned after %v, want it to stop at the deadline", d)
+		}
+	})
+}
+
+func TestExecProviderCachesCredentials(t *testing.T) {
+	defer helperEnv()()
+	dir, err := ioutil.TempDir("", "exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runs := func(counter string) int {
+		b, _ := ioutil.ReadFile(counter)
+		return strings.Count(string(b), "run")
+	}
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		sleep time.Duration
+		want  int
+	}{
+		{"default TTL", 0, 0, 1},
+		{"within the TTL", time.Hour, 0, 1},
+		{"after the TTL", 50 * time.Millisecond, 100 * time.Millisecond, 3},
+		{"negative TTL", -1, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := filepath.Join(dir, strings.Replace(tt.name, " ", "-", -1))
+			provider := helperProvider("count", counter)
+			provider.TTL = tt.ttl
+			for i := 0; i < 3; i++ {
+				if _, err := provider.Retrieve(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(tt.sleep)
+			}
+			if n := runs(counter); n != tt.want {
+				t.Errorf("the command ran %d times, want %d", n, tt.want)
+			}
+		})
+	}
+
+	// a Client retrying a request does not run the command again
+	srv := newTestServer()
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "Status", Times: 2})
+	counter := filepath.Join(dir, "client")
+	client := srv.Client(t, &onetimesecret.ClientOptions{
+		CredentialsProvider: helperProvider("count", counter),
+		RetryPolicy:         &onetimesecret.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := client.Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := runs(counter); n != 1 {
+		t.Errorf("the command ran %d times for 4 requests, want 1", n)
+	}
+}
+
+// staticProvider is a CredentialsProvider that counts its calls and returns fixed credentials or an error
+type staticProvider struct {
+	creds *onetimesecret.Credentials
+	err   error
+	calls int
+}
+
+func (S *staticProvider) Retrieve(ctx context.Context) (*onetimesecret.Credentials, error) {
+	S.calls++
+	return S.creds, S.err
+}
+
+func TestChainProvider(t *testing.T) {
+	alice := &onetimesecret.Credentials{Username: "alice", APIToken: "token"}
+	bob := &onetimesecret.Credentials{Username: "bob", APIToken: "token"}
+	errBroken := errors.New("broken provider")
+
+	tests := []struct {
+		name      string
+		providers []*staticProvider
+		want      *onetimesecret.Credentials
+		wantErr   error
+		wantCalls []int
+	}{
+		{
+			name:      "first with credentials wins",
+			providers: []*staticProvider{{err: onetimesecret.ErrNoCredentials}, {creds: alice}, {creds: bob}},
+			want:      alice,
+			wantCalls: []int{1, 1, 0},
+		},
+		{
+			name:      "an error stops the chain",
+			providers: []*staticProvider{{err: onetimesecret.ErrNoCredentials}, {err: errBroken}, {creds: bob}},
+			wantErr:   errBroken,
+			wantCalls: []int{1, 1, 0},
+		},
+		{
+			name:      "a wrapped ErrNoCredentials is skipped",
+			providers: []*staticProvider{{err: fmt.Errorf("vault: %w", onetimesecret.ErrNoCredentials)}, {creds: bob}},
+			want:      bob,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "none has credentials",
+			providers: []*staticProvider{{err: onetimesecret.ErrNoCredentials}, {err: onetimesecret.ErrNoCredentials}},
+			wantErr:   onetimesecret.ErrNoCredentials,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:    "empty chain",
+			wantErr: onetimesecret.ErrNoCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chain onetimesecret.ChainProvider
+			for _, p := range tt.providers {
+				chain = append(chain, p)
+			}
+
+			creds, err := chain.Retrieve(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Retrieve() = %v, %v, want %v", creds, err, tt.wantErr)
+				}
+			} else if err != nil || creds != tt.want {
+				t.Errorf("Retrieve() = %v, %v, want %v", creds, err, tt.want)
+			}
+			for i, p := range tt.providers {
+				if p.calls != tt.wantCalls[i] {
+					t.Errorf("provider %d was called %d times, want %d", i, p.calls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRotatedTokenIsPickedUp(t *testing.T) {
+	path, cleanup := writeConfig(t, "[default]\nusername = alice\napi_token = old-token\n")
+	defer cleanup()
+
+	srv := otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{"alice": "old-token"}})
+	defer srv.Close()
+	client := srv.Client(t, &onetimesecret.ClientOptions{
+		CredentialsProvider: &onetimesecret.FileProvider{Path: path, Profile: onetimesecret.DefaultProfile},
+	})
+	if _, err := client.Authcheck(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the service rotates the token, so the old one stops working until the config file is updated
+	srv.AddAccount("alice", "new-token")
+	if _, err := client.Authcheck(); !errors.Is(err, onetimesecret.ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized with the old token", err)
+	}
+	if err := onetimesecret.SaveProfile(path, &onetimesecret.Profile{Name: onetimesecret.DefaultProfile, Username: "alice", APIToken: "new-token"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Authcheck(); err != nil {
+		t.Errorf("Authcheck with the rotated token: %v", err)
+	}
+}
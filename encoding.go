@@ -0,0 +1,109 @@
+package onetimesecret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values will encode the request into the form parameters expected by the https://onetimesecret.com/api/v1/share endpoint
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (url.Values): The encoded form parameters
+func (C *CreateSecretRequest) Values() url.Values {
+	v := url.Values{}
+	v.Set("secret", C.Secret)
+	if C.Passphrase != "" {
+		v.Set("passphrase", C.Passphrase)
+	}
+	if C.TTL != 0 {
+		v.Set("ttl", strconv.FormatInt(int64(C.TTL/time.Second), 10))
+	}
+	for _, r := range C.Recipient {
+		v.Add("recipient", r)
+	}
+	return v
+}
+
+// Values will encode the request into the form parameters expected by the https://onetimesecret.com/api/v1/generate endpoint
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (url.Values): The encoded form parameters
+func (G *GenerateSecretRequest) Values() url.Values {
+	v := url.Values{}
+	if G.Passphrase != "" {
+		v.Set("passphrase", G.Passphrase)
+	}
+	if G.TTL != 0 {
+		v.Set("ttl", strconv.FormatInt(int64(G.TTL/time.Second), 10))
+	}
+	for _, r := range G.Recipient {
+		v.Add("recipient", r)
+	}
+	return v
+}
+
+// Values will encode the request into the form parameters expected by the https://onetimesecret.com/api/v1/secret/SECRET_KEY endpoint
+//
+// The secret key itself is part of the path and is not included.
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (url.Values): The encoded form parameters
+func (R *RetrieveSecretRequest) Values() url.Values {
+	v := url.Values{}
+	if R.Passphrase != "" {
+		v.Set("passphrase", R.Passphrase)
+	}
+	return v
+}
+
+// newRequest builds the request for an operation, with its params encoded by the Client's codec
+//
+// The key is escaped with url.PathEscape before being put into the path so that it can never alter the endpoint.
+func (C *Client) newRequest(ctx context.Context, op *operation) (*http.Request, error) {
+	u := C.otsURL + strings.Replace(op.path, keyParam, url.PathEscape(op.key), 1)
+
+	b, contentType, err := C.codec.encode(op)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if b != nil {
+		body = bytes.NewReader(b)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, op.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if C.creds != nil {
+		creds, err := C.creds.Retrieve(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving credentials: %w", err)
+		}
+		if creds == nil {
+			return nil, fmt.Errorf("retrieving credentials: %w", ErrNoCredentials)
+		}
+		httpReq.SetBasicAuth(creds.Username, creds.APIToken)
+	}
+	return httpReq, nil
+}
@@ -0,0 +1,125 @@
+package onetimesecret_test
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// roundTripSecrets are secrets that must survive being shared and retrieved unchanged
+var roundTripSecrets = map[string]string{
+	"ampersand":     "a&b=c&secret=d",
+	"hash":          "p#ss#word",
+	"spaces":        "  leading, inner  and trailing  ",
+	"plus":          "1+1 = 2",
+	"percent":       "100%25 %zz",
+	"newlines":      "line 1\nline 2\r\n\ttabbed",
+	"quotes":        `"double" 'single' \backslash`,
+	"unicode":       "pässwörd ключ 秘密 🔑",
+	"control":       "nul\x00bell\x07esc\x1b",
+	"base64 binary": base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0xfe, 0x80, 0x7f, '&', '#'}),
+}
+
+func TestCreateSecretRequestValuesRoundTrip(t *testing.T) {
+	secrets := map[string]string{"binary": "\x00\xff\xfe\x80 &#=+%"}
+	for name, secret := range roundTripSecrets {
+		secrets[name] = secret
+	}
+
+	for name, secret := range secrets {
+		t.Run(name, func(t *testing.T) {
+			req := &onetimesecret.CreateSecretRequest{
+				Secret:     secret,
+				Passphrase: secret,
+				TTL:        90 * time.Second,
+				Recipient:  []string{"a+b@example.com", "c@example.com"},
+			}
+
+			v, err := url.ParseQuery(req.Values().Encode())
+			if err != nil {
+				t.Fatalf("parsing the encoded form: %v", err)
+			}
+			if got := v.Get("secret"); got != secret {
+				t.Errorf("secret = %q, want %q", got, secret)
+			}
+			if got := v.Get("passphrase"); got != secret {
+				t.Errorf("passphrase = %q, want %q", got, secret)
+			}
+			if got := v.Get("ttl"); got != "90" {
+				t.Errorf("ttl = %q, want %q", got, "90")
+			}
+			if got := v["recipient"]; len(got) != 2 || got[0] != "a+b@example.com" || got[1] != "c@example.com" {
+				t.Errorf("recipient = %q", got)
+			}
+		})
+	}
+}
+
+func TestGenerateAndRetrieveSecretRequestValues(t *testing.T) {
+	g := (&onetimesecret.GenerateSecretRequest{Passphrase: "a&b #c"}).Values()
+	if _, ok := g["secret"]; ok {
+		t.Errorf("GenerateSecretRequest sends a secret: %v", g)
+	}
+	if _, ok := g["ttl"]; ok {
+		t.Errorf("GenerateSecretRequest sends a zero ttl: %v", g)
+	}
+	if v, _ := url.ParseQuery(g.Encode()); v.Get("passphrase") != "a&b #c" {
+		t.Errorf("passphrase = %q", v.Get("passphrase"))
+	}
+
+	r := (&onetimesecret.RetrieveSecretRequest{SecretKey: "key", Passphrase: "p&q=r"}).Values()
+	if _, ok := r["secret_key"]; ok {
+		t.Errorf("RetrieveSecretRequest sends its key in the body: %v", r)
+	}
+	if v, _ := url.ParseQuery(r.Encode()); v.Get("passphrase") != "p&q=r" {
+		t.Errorf("passphrase = %q", v.Get("passphrase"))
+	}
+}
+
+func TestSecretRoundTripThroughServer(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client(t, nil)
+
+	for name, secret := range roundTripSecrets {
+		t.Run(name, func(t *testing.T) {
+			created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: secret, Passphrase: secret})
+			if err != nil {
+				t.Fatalf("CreateSecret: %v", err)
+			}
+			resp, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{
+				SecretKey:  created.SecretKey,
+				Passphrase: secret,
+			})
+			if err != nil {
+				t.Fatalf("RetrieveSecret: %v", err)
+			}
+			if resp.SecretValue != secret {
+				t.Errorf("retrieved %q, want %q", resp.SecretValue, secret)
+			}
+		})
+	}
+}
+
+func TestInvalidUTF8SecretIsReplaced(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client(t, nil)
+
+	// the documented limit of CreateSecretRequest: responses are JSON, which can not carry invalid UTF-8
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "a\xffb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SecretValue != "a\ufffdb" {
+		t.Errorf("retrieved %q, want %q", resp.SecretValue, "a\ufffdb")
+	}
+}
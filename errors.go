@@ -0,0 +1,126 @@
+package onetimesecret
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors that errors returned by a Client can be matched against with errors.Is
+var (
+	// ErrSecretNotFound is returned when the secret or metadata does not exist, has already been viewed or has expired
+	ErrSecretNotFound = errors.New("secret not found")
+
+	// ErrUnauthorized is returned when the service rejects the configured Credentials
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrRateLimited is returned when the service is throttling requests
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrPassphraseRequired is returned when a secret needs a passphrase that was missing or incorrect
+	ErrPassphraseRequired = errors.New("passphrase required")
+
+	// ErrServerError is returned when the service fails with a 5xx status code
+	ErrServerError = errors.New("server error")
+
+	// ErrAuthenticationRequired is returned, without contacting the service, when an anonymous Client performs a call
+	// that needs an account, such as RetrieveRecentMetadata or Authcheck
+	ErrAuthenticationRequired = errors.New("credentials are required for this operation")
+)
+
+// APIError is returned whenever the service responds with a non-200 status code
+//
+//  Attributes
+//
+//    StatusCode: the HTTP status code the service returned.
+//    Endpoint: the method and path of the request, e.g. "POST /api/v1/share", with any key replaced by "{key}".
+//    Message: the "message" field decoded from the response body, if there was one.
+//    Body: the raw response body.
+type APIError struct {
+	StatusCode int
+	Endpoint   string
+	Message    string
+	Body       []byte
+
+	// passphraseSent is set when the failed request was a RetrieveSecret carrying a passphrase
+	passphraseSent bool
+}
+
+// Error will describe the failed request
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (string): The error message
+func (A *APIError) Error() string {
+	if A.Message == "" {
+		return fmt.Sprintf("%s: service returned a non-200 status code: %d", A.Endpoint, A.StatusCode)
+	}
+	return fmt.Sprintf("%s: service returned a non-200 status code: %d: %s", A.Endpoint, A.StatusCode, A.Message)
+}
+
+// Is will report whether the error matches one of the package sentinel errors, for use with errors.Is
+//
+// The service answers a missing or wrong passphrase with 404 Not Found, like an unknown secret. ErrPassphraseRequired
+// is told apart by the message of the response mentioning a passphrase, as in "A passphrase is required to view this
+// secret" or "Incorrect passphrase". Since the service may also answer a wrong passphrase with just "Unknown secret",
+// a 404 to a RetrieveSecret that sent a passphrase matches ErrPassphraseRequired too, whatever its message.
+//
+// Variables:
+//     target (error): The error to compare against
+//
+// Returns:
+//     (bool): true if the error matches target, false otherwise
+func (A *APIError) Is(target error) bool {
+	switch target {
+	case ErrPassphraseRequired:
+		if A.StatusCode == http.StatusNotFound && A.passphraseSent {
+			return true
+		}
+		return strings.Contains(strings.ToLower(A.Message), "passphrase")
+	case ErrSecretNotFound:
+		return A.StatusCode == http.StatusNotFound && !A.Is(ErrPassphraseRequired)
+	case ErrUnauthorized:
+		return A.StatusCode == http.StatusUnauthorized || A.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return A.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return A.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// checkResponse returns nil for a 200 response and an *APIError built from the response body otherwise
+//
+// The Endpoint of the error has the key of the operation replaced by "{key}", so that printing the error never
+// reveals a secret or metadata key.
+func checkResponse(op *operation, httpResp *http.Response) error {
+	if httpResp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: httpResp.StatusCode,
+		Endpoint:   op.method + " " + op.endpoint(),
+
+		passphraseSent: op.name == "RetrieveSecret" && op.params.Get("passphrase") != "",
+	}
+
+	b, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return apiErr
+	}
+	apiErr.Body = b
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(b, &body) == nil {
+		apiErr.Message = body.Message
+	}
+	return apiErr
+}
@@ -0,0 +1,89 @@
+package onetimesecret_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestAPIErrorHidesKey(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client(t, nil)
+
+	const key = "0123456789abcdef0123456789abcdef"
+	_, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: key})
+
+	var apiErr *onetimesecret.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+	if want := "POST /api/v1/secret/{key}"; apiErr.Endpoint != want {
+		t.Errorf("Endpoint = %q, want %q", apiErr.Endpoint, want)
+	}
+	if strings.Contains(err.Error(), key) {
+		t.Errorf("the error reveals the key: %v", err)
+	}
+}
+
+func TestPassphraseRequired(t *testing.T) {
+	// the messages the service answers a 404 with; a wrong passphrase may get the same "Unknown secret" as a missing
+	// secret
+	tests := []struct {
+		name       string
+		message    string
+		passphrase string
+		want       error
+	}{
+		{"missing passphrase", "A passphrase is required to view this secret", "", onetimesecret.ErrPassphraseRequired},
+		{"wrong passphrase", "Incorrect passphrase", "wrong", onetimesecret.ErrPassphraseRequired},
+		{"wrong passphrase with a generic message", "Unknown secret", "wrong", onetimesecret.ErrPassphraseRequired},
+		{"unknown secret", "Unknown secret", "", onetimesecret.ErrSecretNotFound},
+		{"no message", "", "", onetimesecret.ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"message": %q}`, tt.message)
+			}))
+			defer srv.Close()
+			client, err := onetimesecret.NewWithOptions(&onetimesecret.ClientOptions{
+				Credentials:      &onetimesecret.Credentials{Username: testUsername, APIToken: testAPIToken},
+				OneTimeSecretURL: srv.URL,
+				HTTPClient:       srv.Client(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: "abc", Passphrase: tt.passphrase})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			for _, other := range []error{onetimesecret.ErrPassphraseRequired, onetimesecret.ErrSecretNotFound} {
+				if other != tt.want && errors.Is(err, other) {
+					t.Errorf("err = %v, also matches %v", err, other)
+				}
+			}
+
+			// only RetrieveSecret sends a passphrase, so other 404s are never about one
+			if tt.message == "Unknown secret" {
+				_, err = client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: "abc"})
+				if !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+					t.Errorf("BurnSecret: err = %v, want ErrSecretNotFound", err)
+				}
+			}
+		})
+	}
+}
@@ -26,7 +26,10 @@ func main() {
 		},
 	}
 
-	client := onetimesecret.NewWithOptions(opts)
+	client, err := onetimesecret.NewWithOptions(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	createRequest := &onetimesecret.CreateSecretRequest{
 		Secret:     "abcdefg12345", // This is the only required field
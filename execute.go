@@ -0,0 +1,153 @@
+package onetimesecret
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// operation describes a single call against the service API
+//
+//  Attributes
+//
+//    name: the name of the Client method performing the call, e.g. "CreateSecret".
+//    key: the secret or metadata key the call addresses, if any.
+//    params: the parameters of the call in their v1 form encoding, nil if it has none.
+//    idempotent: whether the call may safely be repeated according to the Client's RetryPolicy.
+//    authenticated: whether the call needs an account, failing with ErrAuthenticationRequired on an anonymous Client.
+//    method: the HTTP method of the call, filled in from the Client's codec.
+//    path: the endpoint path of the call with keyParam standing in for the key, filled in from the Client's codec.
+type operation struct {
+	name          string
+	key           string
+	params        url.Values
+	idempotent    bool
+	authenticated bool
+	method        string
+	path          string
+}
+
+// keyParam marks the position of the operation key in its path
+const keyParam = "{key}"
+
+// endpoint returns the path of the operation with the key left as a placeholder, which is safe to log
+func (op *operation) endpoint() string {
+	return op.path
+}
+
+// unmarshaler is implemented by every response type
+type unmarshaler interface {
+	Unmarshal(httpResponseBody io.ReadCloser) error
+}
+
+// execute is the single execution path shared by every Client method
+//
+// It sends the operation through the interceptor chain, retrying idempotent operations, and unmarshals a 200
+// response into resp or returns an *APIError otherwise. The call is traced and measured if the Client has a Tracer
+// or Metrics.
+func (C *Client) execute(ctx context.Context, op *operation, resp unmarshaler) error {
+	if op.authenticated && C.Anonymous() {
+		return ErrAuthenticationRequired
+	}
+
+	op.method, op.path = C.codec.route(op.name)
+	ctx = context.WithValue(ctx, operationKey{}, op)
+
+	var span Span
+	if C.tracer != nil {
+		ctx, span = C.tracer.Start(ctx, op.name)
+	}
+
+	start := time.Now()
+	statusCode, attempts, err := C.perform(ctx, op, resp)
+	C.instrument(span, op, statusCode, attempts, time.Since(start), err)
+	return err
+}
+
+// perform runs the operation and returns the status code of the final response, if any, and the number of attempts made
+func (C *Client) perform(ctx context.Context, op *operation, resp unmarshaler) (int, int, error) {
+	httpResp, attempts, err := C.do(ctx, op)
+	if err != nil {
+		return 0, attempts, err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkResponse(op, httpResp); err != nil {
+		return httpResp.StatusCode, attempts, err
+	}
+
+	b, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return httpResp.StatusCode, attempts, err
+	}
+
+	b, err = C.codec.decode(op, b)
+	if err != nil {
+		return httpResp.StatusCode, attempts, err
+	}
+
+	return httpResp.StatusCode, attempts, resp.Unmarshal(ioutil.NopCloser(bytes.NewReader(b)))
+}
+
+// do performs the operation, repeating it according to the Client's RetryPolicy if it is idempotent
+//
+// A fresh request is built for every attempt so that every attempt gets its own body. The response of the final
+// attempt is returned alongside the number of attempts made.
+func (C *Client) do(ctx context.Context, op *operation) (*http.Response, int, error) {
+	attempts := 1
+	if op.idempotent {
+		attempts = C.retryPolicy.attempts()
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := C.newRequest(ctx, op)
+		if err != nil {
+			return nil, attempt, err
+		}
+
+		httpResp, err := C.send(httpReq)
+		if attempt+1 >= attempts || !retryable(ctx, httpResp, err) {
+			return httpResp, attempt + 1, err
+		}
+
+		wait, ok := retryAfter(httpResp)
+		switch {
+		case !ok:
+			wait = C.retryPolicy.backoff(attempt)
+		case C.retryPolicy.MaxBackoff > 0 && wait > C.retryPolicy.MaxBackoff:
+			wait = C.retryPolicy.MaxBackoff
+		}
+		if httpResp != nil {
+			io.Copy(ioutil.Discard, httpResp.Body)
+			httpResp.Body.Close()
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, attempt + 1, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// send performs a single attempt: it waits on the Client's RateLimiter, if any, and passes the request through the
+// interceptor chain to the HTTP client
+func (C *Client) send(httpReq *http.Request) (*http.Response, error) {
+	if C.limiter != nil {
+		if err := C.limiter.Wait(httpReq.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	httpResp, err := C.handler(httpReq)
+	if err == nil && C.limiter != nil {
+		C.limiter.observe(httpResp)
+	}
+	return httpResp, err
+}
@@ -0,0 +1,52 @@
+package onetimesecret_test
+
+import (
+	"os"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// the account of the fake servers of the tests
+const (
+	testUsername = "alice"
+	testAPIToken = "token"
+)
+
+// newTestServer returns a fake server that knows the test account
+func newTestServer() *otstest.Server {
+	return otstest.NewServer(&otstest.ServerOptions{Accounts: map[string]string{testUsername: testAPIToken}})
+}
+
+// newTestClient returns a Client of srv that authenticates with the test account, with the other options taken from
+// opts, which may be nil
+func newTestClient(t *testing.T, srv *otstest.Server, opts *onetimesecret.ClientOptions) *onetimesecret.Client {
+	t.Helper()
+	var o onetimesecret.ClientOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.Credentials = &onetimesecret.Credentials{Username: testUsername, APIToken: testAPIToken}
+	return srv.Client(t, &o)
+}
+
+// setenv sets an environment variable for the rest of a test, returning a func that restores it
+func setenv(key, value string) func() {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+// unsetenv unsets an environment variable for the rest of a test, returning a func that restores it
+func unsetenv(key string) func() {
+	restore := setenv(key, "")
+	os.Unsetenv(key)
+	return restore
+}
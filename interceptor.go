@@ -0,0 +1,62 @@
+package onetimesecret
+
+import (
+	"context"
+	"net/http"
+)
+
+// Handler sends a single request to the service and returns its response
+type Handler func(httpReq *http.Request) (*http.Response, error)
+
+// Interceptor wraps every request a Client sends, including each retry attempt
+//
+// An Interceptor may inspect or modify the request before passing it to next, inspect the response or error that
+// next returns, or return a response of its own without calling next at all. Interceptors given in
+// ClientOptions.Interceptors run in order, the first one being the outermost.
+type Interceptor func(httpReq *http.Request, next Handler) (*http.Response, error)
+
+// operationKey is the context key that holds the operation being performed
+type operationKey struct{}
+
+// OperationFromContext will return the name of the Client method a request belongs to, e.g. "CreateSecret"
+//
+// Variables:
+//     ctx (context.Context): The context of a request passed to an Interceptor
+//
+// Returns:
+//     (string): The name of the operation
+//     (bool):   true if ctx belongs to a Client request, false otherwise
+func OperationFromContext(ctx context.Context) (string, bool) {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return "", false
+	}
+	return op.name, true
+}
+
+// SetHeader will generate an Interceptor that sets a header on every request
+//
+// Variables:
+//     key (string):   The header name
+//     value (string): The header value
+//
+// Returns:
+//     (Interceptor): The Interceptor
+func SetHeader(key, value string) Interceptor {
+	return func(httpReq *http.Request, next Handler) (*http.Response, error) {
+		httpReq.Header.Set(key, value)
+		return next(httpReq)
+	}
+}
+
+// chain builds the Handler that passes a request through the interceptors before handing it to final
+func chain(interceptors []Interceptor, final Handler) Handler {
+	h := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(httpReq *http.Request) (*http.Response, error) {
+			return interceptor(httpReq, next)
+		}
+	}
+	return h
+}
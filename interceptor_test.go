@@ -0,0 +1,161 @@
+package onetimesecret_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// headerRecorder is an http.RoundTripper that records the headers of the requests it sends
+type headerRecorder struct {
+	mu        sync.Mutex
+	transport http.RoundTripper
+	headers   []http.Header
+}
+
+func (H *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	H.mu.Lock()
+	H.headers = append(H.headers, req.Header.Clone())
+	H.mu.Unlock()
+	return H.transport.RoundTrip(req)
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	var calls []string
+	trace := func(name string) onetimesecret.Interceptor {
+		return func(req *http.Request, next onetimesecret.Handler) (*http.Response, error) {
+			op, _ := onetimesecret.OperationFromContext(req.Context())
+			calls = append(calls, name+" before "+op)
+			resp, err := next(req)
+			calls = append(calls, name+" after "+op)
+			return resp, err
+		}
+	}
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{
+		Interceptors: []onetimesecret.Interceptor{trace("outer"), trace("inner")},
+	})
+
+	if _, err := client.Status(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"outer before Status", "inner before Status", "inner after Status", "outer after Status"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestInterceptorRunsForEveryAttempt(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "Status", Times: 2})
+
+	attempts := 0
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{
+		RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		Interceptors: []onetimesecret.Interceptor{func(req *http.Request, next onetimesecret.Handler) (*http.Response, error) {
+			attempts++
+			return next(req)
+		}},
+	})
+
+	if _, err := client.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("the interceptor ran %d times, want 3", attempts)
+	}
+}
+
+func TestInterceptorInjectsHeaders(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	rec := &headerRecorder{transport: srv.HTTPClient().Transport}
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{
+		HTTPClient: &http.Client{Transport: rec},
+		Interceptors: []onetimesecret.Interceptor{
+			onetimesecret.SetHeader("X-Request-Id", "first"),
+			// a later interceptor sees, and may override, what an earlier one set
+			func(req *http.Request, next onetimesecret.Handler) (*http.Response, error) {
+				req.Header.Set("X-Trace", req.Header.Get("X-Request-Id")+"-traced")
+				return next(req)
+			},
+			onetimesecret.SetHeader("X-Request-Id", "second"),
+		},
+	})
+
+	if _, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.headers) != 1 {
+		t.Fatalf("%d requests sent, want 1", len(rec.headers))
+	}
+	h := rec.headers[0]
+	if got := h.Get("X-Request-Id"); got != "second" {
+		t.Errorf("X-Request-Id = %q, want %q", got, "second")
+	}
+	if got := h.Get("X-Trace"); got != "first-traced" {
+		t.Errorf("X-Trace = %q, want %q", got, "first-traced")
+	}
+	if h.Get("Authorization") == "" {
+		t.Error("the interceptors dropped the Authorization header")
+	}
+}
+
+func TestInterceptorShortCircuits(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	errBlocked := errors.New("blocked by policy")
+	innerCalled := false
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{
+		RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		Interceptors: []onetimesecret.Interceptor{
+			func(req *http.Request, next onetimesecret.Handler) (*http.Response, error) {
+				if op, _ := onetimesecret.OperationFromContext(req.Context()); op == "BurnSecret" {
+					return nil, errBlocked
+				}
+				return next(req)
+			},
+			func(req *http.Request, next onetimesecret.Handler) (*http.Response, error) {
+				innerCalled = true
+				return next(req)
+			},
+		},
+	})
+
+	_, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: "0123456789abcdef0123456789abcdef"})
+	if !errors.Is(err, errBlocked) {
+		t.Errorf("err = %v, want %v", err, errBlocked)
+	}
+	if innerCalled {
+		t.Error("the inner interceptor ran after the outer one returned")
+	}
+	if n := srv.Requests(""); n != 0 {
+		t.Errorf("%d requests reached the server, want 0", n)
+	}
+
+	// other calls pass through
+	if _, err := client.Status(); err != nil {
+		t.Errorf("Status: %v", err)
+	}
+	if !innerCalled {
+		t.Error("the inner interceptor did not run for Status")
+	}
+}
+
+func TestOperationFromContext(t *testing.T) {
+	if op, ok := onetimesecret.OperationFromContext(context.Background()); ok || op != "" {
+		t.Errorf("OperationFromContext(Background) = %q, %v, want \"\", false", op, ok)
+	}
+}
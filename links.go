@@ -0,0 +1,192 @@
+package onetimesecret
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// LinkKind tells a share link from a private link
+type LinkKind string
+
+const (
+	// LinkSecret is a share link, /secret/SECRET_KEY, that is given to the recipient
+	LinkSecret LinkKind = "secret"
+
+	// LinkPrivate is a private link, /private/METADATA_KEY, that shows the creator the state of a secret
+	LinkPrivate LinkKind = "private"
+)
+
+// Link is a parsed share or private link
+//
+//  Attributes
+//
+//    BaseURL: the base URL of the service the link belongs to, e.g. "https://eu.onetimesecret.com".
+//    Kind: whether the link is a share link or a private link.
+//    Key: the secret key of a share link or the metadata key of a private link.
+type Link struct {
+	BaseURL string
+	Kind    LinkKind
+	Key     string
+}
+
+// linkPaths maps the path segment that precedes the key in a link to the kind of link. "receipt" is how newer
+// deployments name private links.
+var linkPaths = map[string]LinkKind{
+	"secret":  LinkSecret,
+	"private": LinkPrivate,
+	"receipt": LinkPrivate,
+}
+
+// ParseLink will parse a share or private link of any region or deployment
+//
+// Anything after the key, such as the "/burn" of a burn link, is ignored, as are a query and a fragment. Anything
+// before the "/secret/" or "/private/" segment is kept in BaseURL, for deployments served below the root of their
+// domain. A link without a scheme, e.g. "onetimesecret.com/secret/abc123", is taken to be https.
+//
+// Variables:
+//     link (string): The link to parse, e.g. "https://onetimesecret.com/secret/abc123"
+//
+// Returns:
+//     (*Link):  A pointer to the parsed Link, nil if an error occurred
+//     (error):  An error if the string is not a share or private link, nil otherwise
+func ParseLink(link string) (*Link, error) {
+	raw := strings.TrimSpace(link)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + strings.TrimLeft(raw, "/")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid link %q: not an http or https URL", link)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		kind, ok := linkPaths[segments[i]]
+		if !ok || segments[i+1] == "" {
+			continue
+		}
+
+		base := url.URL{Scheme: u.Scheme, Host: u.Host}
+		if i > 0 {
+			base.Path = "/" + strings.Join(segments[:i], "/")
+		}
+		return &Link{
+			BaseURL: base.String(),
+			Kind:    kind,
+			Key:     segments[i+1],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid link %q: no /secret/ or /private/ key", link)
+}
+
+// String will build the canonical form of the link
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (string): The link
+func (L *Link) String() string {
+	return L.BaseURL + "/" + string(L.Kind) + "/" + url.PathEscape(L.Key)
+}
+
+// RetrieveSecretRequest will build the request to retrieve the secret of a share link
+//
+// Variables:
+//     passphrase (string): The passphrase of the secret, "" if it has none
+//
+// Returns:
+//     (*RetrieveSecretRequest): A pointer to the request, nil if an error occurred
+//     (error):                  An error if the link is not a share link, nil otherwise
+func (L *Link) RetrieveSecretRequest(passphrase string) (*RetrieveSecretRequest, error) {
+	if L.Kind != LinkSecret {
+		return nil, fmt.Errorf("%s is not a share link", L)
+	}
+	return &RetrieveSecretRequest{SecretKey: L.Key, Passphrase: passphrase}, nil
+}
+
+// RetrieveMetadataRequest will build the request to retrieve the metadata of a private link
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*RetrieveMetadataRequest): A pointer to the request, nil if an error occurred
+//     (error):                    An error if the link is not a private link, nil otherwise
+func (L *Link) RetrieveMetadataRequest() (*RetrieveMetadataRequest, error) {
+	if L.Kind != LinkPrivate {
+		return nil, fmt.Errorf("%s is not a private link", L)
+	}
+	return &RetrieveMetadataRequest{MetadataKey: L.Key}, nil
+}
+
+// BurnSecretRequest will build the request to burn the secret of a private link
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*BurnSecretRequest): A pointer to the request, nil if an error occurred
+//     (error):              An error if the link is not a private link, nil otherwise
+func (L *Link) BurnSecretRequest() (*BurnSecretRequest, error) {
+	if L.Kind != LinkPrivate {
+		return nil, fmt.Errorf("%s is not a private link", L)
+	}
+	return &BurnSecretRequest{MetadataKey: L.Key}, nil
+}
+
+// ShareLink will build the link to give to the recipient of a secret on the Client's service
+//
+// Variables:
+//     secretKey (string): The secret key
+//
+// Returns:
+//     (string): The share link
+func (C *Client) ShareLink(secretKey string) string {
+	return (&Link{BaseURL: C.otsURL, Kind: LinkSecret, Key: secretKey}).String()
+}
+
+// PrivateLink will build the link that shows the creator the state of a secret on the Client's service
+//
+// Variables:
+//     metadataKey (string): The metadata key
+//
+// Returns:
+//     (string): The private link
+func (C *Client) PrivateLink(metadataKey string) string {
+	return (&Link{BaseURL: C.otsURL, Kind: LinkPrivate, Key: metadataKey}).String()
+}
+
+// ShareLink will build the link to give to the recipient of the secret
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (string): The share link on the service the Metadata came from, "" if the Metadata has no SecretKey
+func (M *Metadata) ShareLink() string {
+	if M.SecretKey == "" {
+		return ""
+	}
+	return (&Link{BaseURL: M.baseURL, Kind: LinkSecret, Key: M.SecretKey}).String()
+}
+
+// PrivateLink will build the link that shows the state of the secret. DO NOT share it.
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (string): The private link on the service the Metadata came from, "" if the Metadata has no MetadataKey
+func (M *Metadata) PrivateLink() string {
+	if M.MetadataKey == "" {
+		return ""
+	}
+	return (&Link{BaseURL: M.baseURL, Kind: LinkPrivate, Key: M.MetadataKey}).String()
+}
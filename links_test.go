@@ -0,0 +1,104 @@
+package onetimesecret_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+func TestParseLink(t *testing.T) {
+	const key = "abc123"
+	secret := func(base string) *onetimesecret.Link {
+		return &onetimesecret.Link{BaseURL: base, Kind: onetimesecret.LinkSecret, Key: key}
+	}
+	private := func(base string) *onetimesecret.Link {
+		return &onetimesecret.Link{BaseURL: base, Kind: onetimesecret.LinkPrivate, Key: key}
+	}
+
+	tests := []struct {
+		link    string
+		want    *onetimesecret.Link
+		wantErr string
+	}{
+		{link: "https://onetimesecret.com/secret/abc123", want: secret("https://onetimesecret.com")},
+		{link: "https://us.onetimesecret.com/secret/abc123", want: secret("https://us.onetimesecret.com")},
+		{link: "https://eu.onetimesecret.com/secret/abc123", want: secret("https://eu.onetimesecret.com")},
+		{link: "https://ca.onetimesecret.com/secret/abc123", want: secret("https://ca.onetimesecret.com")},
+		{link: "https://nz.onetimesecret.com/secret/abc123", want: secret("https://nz.onetimesecret.com")},
+		{link: "https://eu.onetimesecret.com/private/abc123", want: private("https://eu.onetimesecret.com")},
+		{link: "https://eu.onetimesecret.com/receipt/abc123", want: private("https://eu.onetimesecret.com")},
+		{link: "http://localhost:7143/secret/abc123", want: secret("http://localhost:7143")},
+		{link: "https://example.com/ots/secret/abc123", want: secret("https://example.com/ots")},
+
+		// no scheme
+		{link: "onetimesecret.com/secret/abc123", want: secret("https://onetimesecret.com")},
+		{link: "eu.onetimesecret.com/private/abc123", want: private("https://eu.onetimesecret.com")},
+		{link: "//onetimesecret.com/secret/abc123", want: secret("https://onetimesecret.com")},
+		{link: "localhost:7143/secret/abc123", want: secret("https://localhost:7143")},
+
+		// anything after the key
+		{link: "https://onetimesecret.com/secret/abc123/", want: secret("https://onetimesecret.com")},
+		{link: "https://onetimesecret.com/private/abc123/burn", want: private("https://onetimesecret.com")},
+		{link: "https://onetimesecret.com/secret/abc123?utm_source=mail", want: secret("https://onetimesecret.com")},
+		{link: "https://onetimesecret.com/secret/abc123#top", want: secret("https://onetimesecret.com")},
+		{link: "  https://onetimesecret.com/secret/abc123\n", want: secret("https://onetimesecret.com")},
+
+		{link: "https://onetimesecret.com/secret/", wantErr: "no /secret/ or /private/ key"},
+		{link: "https://onetimesecret.com/private//burn", wantErr: "no /secret/ or /private/ key"},
+		{link: "https://onetimesecret.com/about/abc123", wantErr: "no /secret/ or /private/ key"},
+		{link: "https://onetimesecret.com/", wantErr: "no /secret/ or /private/ key"},
+		{link: "abc123", wantErr: "no /secret/ or /private/ key"},
+		{link: "ftp://onetimesecret.com/secret/abc123", wantErr: "not an http or https URL"},
+		{link: "https:///secret/abc123", wantErr: "not an http or https URL"},
+		{link: "https://onetimesecret.com/secret/%zz", wantErr: "invalid link"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			got, err := onetimesecret.ParseLink(tt.link)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseLink(%q) = %+v, %v, want an error containing %q", tt.link, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLink(%q) = %+v, %v, want %+v", tt.link, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkRequests(t *testing.T) {
+	share, err := onetimesecret.ParseLink("eu.onetimesecret.com/secret/abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := share.String(), "https://eu.onetimesecret.com/secret/abc123"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if req, err := share.RetrieveSecretRequest("pw"); err != nil || req.SecretKey != "abc123" || req.Passphrase != "pw" {
+		t.Errorf("RetrieveSecretRequest() = %+v, %v, want the key and passphrase", req, err)
+	}
+	if _, err := share.BurnSecretRequest(); err == nil {
+		t.Error("BurnSecretRequest() of a share link succeeded")
+	}
+
+	private, err := onetimesecret.ParseLink("https://eu.onetimesecret.com/receipt/def456/burn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := private.String(), "https://eu.onetimesecret.com/private/def456"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if req, err := private.RetrieveMetadataRequest(); err != nil || req.MetadataKey != "def456" {
+		t.Errorf("RetrieveMetadataRequest() = %+v, %v, want the key", req, err)
+	}
+	if req, err := private.BurnSecretRequest(); err != nil || req.MetadataKey != "def456" {
+		t.Errorf("BurnSecretRequest() = %+v, %v, want the key", req, err)
+	}
+	if _, err := private.RetrieveSecretRequest(""); err == nil {
+		t.Error("RetrieveSecretRequest() of a private link succeeded")
+	}
+}
@@ -0,0 +1,92 @@
+package onetimesecret
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Logger is the subset of *slog.Logger that a Client logs to, so a *slog.Logger can be used directly
+//
+// Args are alternating keys and values, as with slog. Every request sent to the service is logged with its
+// operation, method, endpoint, status and latency. Secrets, passphrases, secret keys, metadata keys and secret
+// values are never passed to the Logger: endpoints are logged with the key replaced by "{key}" and any of those
+// values found in an error message are replaced by "[REDACTED]".
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// redacted replaces sensitive values in anything passed to a Logger
+const redacted = "[REDACTED]"
+
+// sensitive returns the values of the operation that must never be logged
+func (op *operation) sensitive() []string {
+	values := []string{op.key}
+	for _, param := range []string{"secret", "passphrase"} {
+		values = append(values, op.params[param]...)
+	}
+	return values
+}
+
+// redact replaces every sensitive value of the operation found in s, whether verbatim or escaped as in a URL
+func (op *operation) redact(s string) string {
+	for _, v := range op.sensitive() {
+		if v == "" {
+			continue
+		}
+		for _, form := range []string{v, url.PathEscape(v), url.QueryEscape(v)} {
+			s = strings.Replace(s, form, redacted, -1)
+		}
+	}
+	return s
+}
+
+// redactedError masks the sensitive values of an operation in the message of err, while errors.Is and errors.As
+// still see the original error
+type redactedError struct {
+	op  *operation
+	err error
+}
+
+// Error will return the message of the wrapped error with sensitive values replaced by "[REDACTED]"
+func (R *redactedError) Error() string {
+	return R.op.redact(R.err.Error())
+}
+
+// Unwrap will return the wrapped error
+func (R *redactedError) Unwrap() error {
+	return R.err
+}
+
+// logRequests is the Interceptor installed in front of the user supplied ones when ClientOptions.Logger is set
+func (C *Client) logRequests(httpReq *http.Request, next Handler) (*http.Response, error) {
+	op, _ := httpReq.Context().Value(operationKey{}).(*operation)
+	if op == nil {
+		return next(httpReq)
+	}
+
+	start := time.Now()
+	httpResp, err := next(httpReq)
+	args := []interface{}{
+		"operation", op.name,
+		"method", httpReq.Method,
+		"endpoint", op.endpoint(),
+		"latency", time.Since(start),
+	}
+
+	if err != nil {
+		C.logger.Warn("onetimesecret request failed", append(args, "error", op.redact(err.Error()))...)
+		return httpResp, err
+	}
+
+	args = append(args, "status", httpResp.StatusCode)
+	if httpResp.StatusCode != http.StatusOK {
+		C.logger.Warn("onetimesecret request returned a non-200 status code", args...)
+		return httpResp, err
+	}
+
+	C.logger.Info("onetimesecret request", args...)
+	return httpResp, err
+}
@@ -0,0 +1,156 @@
+//go:build go1.21
+// +build go1.21
+
+package onetimesecret_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+const (
+	logSecret     = "hunter2 & #friends"
+	logPassphrase = "correct horse battery staple"
+)
+
+// newLoggingClient returns a Client of srv logging to a slog text handler that writes to buf
+func newLoggingClient(t *testing.T, srv *otstest.Server, buf *bytes.Buffer, interceptors ...onetimesecret.Interceptor) *onetimesecret.Client {
+	t.Helper()
+	return newTestClient(t, srv, &onetimesecret.ClientOptions{
+		Logger:       slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Interceptors: interceptors,
+	})
+}
+
+// assertNotLogged fails the test if the log contains any of the plaintext values, verbatim or escaped
+func assertNotLogged(t *testing.T, log string, values ...string) {
+	t.Helper()
+	if log == "" {
+		t.Fatal("nothing was logged")
+	}
+	for _, v := range values {
+		if v == "" {
+			t.Fatal("a value to look for is empty")
+		}
+		for _, form := range []string{v, url.PathEscape(v), url.QueryEscape(v)} {
+			if strings.Contains(log, form) {
+				t.Errorf("the log contains %q:\n%s", form, log)
+			}
+		}
+	}
+}
+
+func TestLoggingRedactsAllOperations(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	var buf bytes.Buffer
+	client := newLoggingClient(t, srv, &buf)
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: logSecret, Passphrase: logPassphrase})
+	if err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	generated, err := client.GenerateSecret(&onetimesecret.GenerateSecretRequest{Passphrase: logPassphrase})
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey, Passphrase: logPassphrase}); err != nil {
+		t.Fatalf("RetrieveSecret: %v", err)
+	}
+	if _, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey}); err != nil {
+		t.Fatalf("RetrieveMetadata: %v", err)
+	}
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: generated.MetadataKey}); err != nil {
+		t.Fatalf("BurnSecret: %v", err)
+	}
+	if _, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{}); err != nil {
+		t.Fatalf("RetrieveRecentMetadata: %v", err)
+	}
+
+	log := buf.String()
+	for _, op := range []string{"CreateSecret", "GenerateSecret", "RetrieveSecret", "RetrieveMetadata", "BurnSecret", "RetrieveRecentMetadata"} {
+		if !strings.Contains(log, "operation="+op+" ") {
+			t.Errorf("%s was not logged:\n%s", op, log)
+		}
+	}
+	if !strings.Contains(log, "/api/v1/secret/{key}") {
+		t.Errorf("the endpoint of RetrieveSecret was not logged as a template:\n%s", log)
+	}
+	assertNotLogged(t, log, logSecret, logPassphrase, created.SecretKey, created.MetadataKey, generated.SecretKey,
+		generated.MetadataKey, generated.Value)
+}
+
+func TestLoggingRedactsNon200Responses(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	var buf bytes.Buffer
+	client := newLoggingClient(t, srv, &buf)
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: logSecret, Passphrase: logPassphrase})
+	if err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	const wrong = "not the passphrase"
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey, Passphrase: wrong}); err == nil {
+		t.Fatal("RetrieveSecret with a wrong passphrase succeeded")
+	}
+	const unknown = "0123456789abcdef0123456789abcdef"
+	if _, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: unknown}); err == nil {
+		t.Fatal("RetrieveMetadata of an unknown key succeeded")
+	}
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: unknown}); err == nil {
+		t.Fatal("BurnSecret of an unknown key succeeded")
+	}
+
+	log := buf.String()
+	if !strings.Contains(log, "status=404") {
+		t.Errorf("the 404 responses were not logged:\n%s", log)
+	}
+	assertNotLogged(t, log, logSecret, logPassphrase, wrong, unknown, created.SecretKey, created.MetadataKey)
+}
+
+func TestLoggingRedactsTransportErrors(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	var buf bytes.Buffer
+
+	// an interceptor behind the logger that fails with everything it knows about the request in its error
+	leaky := func(req *http.Request, next onetimesecret.Handler) (*http.Response, error) {
+		if err := req.ParseForm(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("transport failed for %s %s with %s", req.Method, req.URL, req.PostForm.Encode())
+	}
+	client := newLoggingClient(t, srv, &buf, leaky)
+
+	if _, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: logSecret, Passphrase: logPassphrase}); err == nil {
+		t.Fatal("CreateSecret succeeded through a failing transport")
+	}
+	const key = "fedcba9876543210fedcba9876543210"
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: key, Passphrase: logPassphrase}); err == nil {
+		t.Fatal("RetrieveSecret succeeded through a failing transport")
+	}
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: key}); err == nil {
+		t.Fatal("BurnSecret succeeded through a failing transport")
+	}
+
+	// a reset connection fails in the HTTP client, whose error quotes the URL
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultReset, Endpoint: "RetrieveMetadata"})
+	if _, err := newLoggingClient(t, srv, &buf).RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: key}); err == nil {
+		t.Fatal("RetrieveMetadata succeeded through a reset connection")
+	}
+
+	log := buf.String()
+	if !strings.Contains(log, "request failed") {
+		t.Errorf("the transport errors were not logged:\n%s", log)
+	}
+	assertNotLogged(t, log, logSecret, logPassphrase, key)
+}
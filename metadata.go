@@ -0,0 +1,219 @@
+package onetimesecret
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// State is the lifecycle state of a secret, as seen through its metadata
+type State string
+
+const (
+	// StateUnknown is the state of metadata the service sent no recognizable state for
+	StateUnknown State = "unknown"
+
+	// StateNew is a secret that has not been viewed yet
+	StateNew State = "new"
+
+	// StateViewed is a secret whose share link has been opened but whose value has not been revealed
+	StateViewed State = "viewed"
+
+	// StateReceived is a secret whose value the recipient has revealed. It can not be retrieved again.
+	StateReceived State = "received"
+
+	// StateBurned is a secret that was destroyed before it was received
+	StateBurned State = "burned"
+
+	// StateExpired is a secret whose TTL ran out before it was received
+	StateExpired State = "expired"
+)
+
+// states maps the state names used by the service onto a State. Newer deployments say "previewed" and "revealed"
+// where older ones say "viewed" and "received".
+var states = map[string]State{
+	"new":       StateNew,
+	"viewed":    StateViewed,
+	"previewed": StateViewed,
+	"received":  StateReceived,
+	"revealed":  StateReceived,
+	"burned":    StateBurned,
+	"expired":   StateExpired,
+}
+
+// parseState will map the state field of the service onto a State, falling back to the received time when the
+// service sends no state
+func parseState(state string, received time.Time) State {
+	if s, ok := states[strings.ToLower(strings.TrimSpace(state))]; ok {
+		return s
+	}
+	if state == "" && !received.IsZero() {
+		return StateReceived
+	}
+	return StateUnknown
+}
+
+// Final will report whether the secret can no longer change state
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (bool): true for StateReceived, StateBurned and StateExpired, false otherwise
+func (S State) Final() bool {
+	return S == StateReceived || S == StateBurned || S == StateExpired
+}
+
+// Metadata is what the service tells the creator of a secret about it, and is shared by the responses of every call
+// that creates, looks up or burns a secret
+//
+//  Attributes
+//
+//    custid: this is you :]
+//    metadata_key: the unique key for the metadata. DO NOT share this.
+//    secret_key: the unique key for the secret. This is key that you can share. The service omits it once the
+//                secret is received or burned.
+//    ttl: The time-to-live that was specified (i.e. not the time remaining)
+//    metadata_ttl: The remaining time that the metadata has left to live.
+//    secret_ttl: The remaining time that the secret has left to live.
+//    recipient: if a recipient was specified, this is an obfuscated version of the email address.
+//    created: Time the secret was created
+//    updated: ditto, but the time it was last updated.
+//    received: Time the secret was received, the zero time.Time if it has not been.
+//    passphrase_required: If a passphrase was provided when the secret was created, this will be true. Otherwise false, obviously.
+//    state: where the secret is in its lifecycle.
+type Metadata struct {
+	CustID             string        `json:"custid"`
+	MetadataKey        string        `json:"metadata_key"`
+	SecretKey          string        `json:"secret_key"`
+	TTL                time.Duration `json:"ttl"`
+	MetadataTTL        time.Duration `json:"metadata_ttl"`
+	SecretTTL          time.Duration `json:"secret_ttl"`
+	Recipient          []string      `json:"recipient"`
+	CreatedAt          time.Time     `json:"created"`
+	UpdatedAt          time.Time     `json:"updated"`
+	Received           time.Time     `json:"received"`
+	PassphraseRequired bool          `json:"passphrase_required"`
+	State              State         `json:"state"`
+
+	// baseURL is the service the metadata came from, used to build links
+	baseURL string
+}
+
+// wireMetadata is Metadata as the service sends it, tolerant of the string and number variations of its timestamps
+// and durations
+type wireMetadata struct {
+	CustID             string   `json:"custid"`
+	Value              string   `json:"value,omitempty"`
+	MetadataKey        string   `json:"metadata_key"`
+	SecretKey          string   `json:"secret_key"`
+	TTL                seconds  `json:"ttl"`
+	MetadataTTL        seconds  `json:"metadata_ttl"`
+	SecretTTL          seconds  `json:"secret_ttl"`
+	Recipient          []string `json:"recipient"`
+	CreatedAt          unixTime `json:"created"`
+	UpdatedAt          unixTime `json:"updated"`
+	Received           unixTime `json:"received"`
+	PassphraseRequired bool     `json:"passphrase_required"`
+	State              string   `json:"state"`
+}
+
+// metadata converts the wire form into Metadata
+func (W *wireMetadata) metadata() Metadata {
+	return Metadata{
+		CustID:             W.CustID,
+		MetadataKey:        W.MetadataKey,
+		SecretKey:          W.SecretKey,
+		TTL:                time.Duration(W.TTL),
+		MetadataTTL:        time.Duration(W.MetadataTTL),
+		SecretTTL:          time.Duration(W.SecretTTL),
+		Recipient:          W.Recipient,
+		CreatedAt:          time.Time(W.CreatedAt),
+		UpdatedAt:          time.Time(W.UpdatedAt),
+		Received:           time.Time(W.Received),
+		PassphraseRequired: W.PassphraseRequired,
+		State:              parseState(W.State, time.Time(W.Received)),
+	}
+}
+
+// wire converts Metadata into the form the service sends
+func (M *Metadata) wire() wireMetadata {
+	return wireMetadata{
+		CustID:             M.CustID,
+		MetadataKey:        M.MetadataKey,
+		SecretKey:          M.SecretKey,
+		TTL:                seconds(M.TTL),
+		MetadataTTL:        seconds(M.MetadataTTL),
+		SecretTTL:          seconds(M.SecretTTL),
+		Recipient:          M.Recipient,
+		CreatedAt:          unixTime(M.CreatedAt),
+		UpdatedAt:          unixTime(M.UpdatedAt),
+		Received:           unixTime(M.Received),
+		PassphraseRequired: M.PassphraseRequired,
+		State:              string(M.State),
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting timestamps and durations as numbers or strings
+func (M *Metadata) UnmarshalJSON(b []byte) error {
+	var w wireMetadata
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+
+	baseURL := M.baseURL
+	*M = w.metadata()
+	M.baseURL = baseURL
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the Metadata the way the service sends it
+func (M Metadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(M.wire())
+}
+
+// ExpiresAt will return the time the secret expires if it is not received before
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (time.Time): CreatedAt plus TTL, the zero time.Time if either is unknown
+func (M *Metadata) ExpiresAt() time.Time {
+	if M.CreatedAt.IsZero() || M.TTL == 0 {
+		return time.Time{}
+	}
+	return M.CreatedAt.Add(M.TTL)
+}
+
+// IsReceived will report whether the recipient has revealed the secret
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (bool): true if the secret is in StateReceived or has a Received time, false otherwise
+func (M *Metadata) IsReceived() bool {
+	return M.State == StateReceived || !M.Received.IsZero()
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the generated value alongside the Metadata
+func (G *GenerateSecretResponse) UnmarshalJSON(b []byte) error {
+	var w wireMetadata
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+
+	baseURL := G.baseURL
+	G.Metadata = w.metadata()
+	G.baseURL = baseURL
+	G.Value = w.Value
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the generated value alongside the Metadata
+func (G GenerateSecretResponse) MarshalJSON() ([]byte, error) {
+	w := G.wire()
+	w.Value = G.Value
+	return json.Marshal(w)
+}
@@ -0,0 +1,55 @@
+package onetimesecret_test
+
+import (
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+func TestMetadataExpiresAt(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		meta onetimesecret.Metadata
+		want time.Time
+	}{
+		{"created with a TTL", onetimesecret.Metadata{CreatedAt: created, TTL: time.Hour}, created.Add(time.Hour)},
+		{"shortest TTL", onetimesecret.Metadata{CreatedAt: created, TTL: time.Second}, created.Add(time.Second)},
+		{"no TTL", onetimesecret.Metadata{CreatedAt: created}, time.Time{}},
+		{"no creation time", onetimesecret.Metadata{TTL: time.Hour}, time.Time{}},
+		{"neither", onetimesecret.Metadata{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.ExpiresAt(); !got.Equal(tt.want) || got.IsZero() != tt.want.IsZero() {
+				t.Errorf("ExpiresAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataIsReceived(t *testing.T) {
+	received := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		meta onetimesecret.Metadata
+		want bool
+	}{
+		{"received", onetimesecret.Metadata{State: onetimesecret.StateReceived, Received: received}, true},
+		{"received state without a time", onetimesecret.Metadata{State: onetimesecret.StateReceived}, true},
+		{"received time without the state", onetimesecret.Metadata{State: onetimesecret.StateUnknown, Received: received}, true},
+		{"new", onetimesecret.Metadata{State: onetimesecret.StateNew}, false},
+		{"viewed", onetimesecret.Metadata{State: onetimesecret.StateViewed}, false},
+		{"burned", onetimesecret.Metadata{State: onetimesecret.StateBurned}, false},
+		{"expired", onetimesecret.Metadata{State: onetimesecret.StateExpired}, false},
+		{"zero", onetimesecret.Metadata{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.IsReceived(); got != tt.want {
+				t.Errorf("IsReceived() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package otstest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a manually advanced clock for driving the TTL expiry of a fake Server
+//
+// Pass its Now method as ServerOptions.Now. It is safe for concurrent use.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock will generate a new Clock stopped at the given time
+//
+// Variables:
+//     now (time.Time): The time the Clock starts at
+//
+// Returns:
+//     (*Clock): A pointer to a new instance of Clock
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now will return the current time of the Clock
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (time.Time): The current time of the Clock
+func (C *Clock) Now() time.Time {
+	C.mu.Lock()
+	defer C.mu.Unlock()
+	return C.now
+}
+
+// Advance will move the Clock forward
+//
+// Variables:
+//     d (time.Duration): How far to move the Clock
+//
+// Returns:
+//     None
+func (C *Clock) Advance(d time.Duration) {
+	C.mu.Lock()
+	defer C.mu.Unlock()
+	C.now = C.now.Add(d)
+}
@@ -0,0 +1,198 @@
+package otstest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+// FaultKind names a way the Server can misbehave
+type FaultKind string
+
+const (
+	// FaultRateLimit answers 429 Too Many Requests, with a Retry-After header if Fault.RetryAfter is set
+	FaultRateLimit FaultKind = "rate_limit"
+
+	// FaultServerError answers 500 Internal Server Error
+	FaultServerError FaultKind = "server_error"
+
+	// FaultUnauthorized answers 401 Unauthorized, as if the credentials of the request were bad
+	FaultUnauthorized FaultKind = "unauthorized"
+
+	// FaultDelay holds the request for Fault.Delay, or until the client gives up, before serving it normally
+	FaultDelay FaultKind = "delay"
+
+	// FaultTruncate serves the request normally but cuts the JSON body of the response in half. The request takes
+	// effect, so a created secret exists even though the client could not read its keys.
+	FaultTruncate FaultKind = "truncate"
+
+	// FaultReset resets the connection without answering. The request does not take effect.
+	//
+	// Note that http.Transport transparently resends a GET request whose reused keep-alive connection is reset, so a
+	// reset of Status, Authcheck or RetrieveRecentMetadata may never reach the Client.
+	FaultReset FaultKind = "reset"
+)
+
+// Fault scripts a misbehaviour of the Server
+//
+//  Attributes
+//
+//    Kind: how the Server misbehaves.
+//    Endpoint: the endpoint the Fault applies to, named after the Client call it serves, e.g. "CreateSecret" or
+//              "RetrieveSecret". "" applies to every endpoint.
+//    After: how many matching requests are served normally before the Fault fires, so After: 2 fails the third.
+//    Times: how many matching requests the Fault fires for once it starts, 0 for every one after that.
+//    RetryAfter: the Retry-After header of FaultRateLimit, rounded up to whole seconds. 0 sends no header.
+//    Delay: how long FaultDelay holds a request.
+type Fault struct {
+	Kind       FaultKind
+	Endpoint   string
+	After      int
+	Times      int
+	RetryAfter time.Duration
+	Delay      time.Duration
+}
+
+// fault is an injected Fault along with how often it has matched and fired
+type fault struct {
+	Fault
+	seen  int
+	fired int
+}
+
+// InjectFault will add a Fault to the script of the Server
+//
+// When several Faults fire for the same request, the first one injected wins. Every matching Fault still counts the
+// request towards its After, while only the requests it fires for count towards its Times.
+//
+// Variables:
+//     f (Fault): The Fault to inject
+//
+// Returns:
+//     None
+func (S *Server) InjectFault(f Fault) {
+	S.faultsMu.Lock()
+	defer S.faultsMu.Unlock()
+	S.faults = append(S.faults, &fault{Fault: f})
+}
+
+// ClearFaults will remove every Fault from the script of the Server, so that it behaves again
+//
+// Variables:
+//     None
+//
+// Returns:
+//     None
+func (S *Server) ClearFaults() {
+	S.faultsMu.Lock()
+	defer S.faultsMu.Unlock()
+	S.faults = nil
+}
+
+// Requests will count the requests the Server has received, faulted or not
+//
+// Variables:
+//     endpoint (string): The endpoint to count the requests of, e.g. "BurnSecret", "" for every request
+//
+// Returns:
+//     (int): The number of requests
+func (S *Server) Requests(endpoint string) int {
+	S.faultsMu.Lock()
+	defer S.faultsMu.Unlock()
+
+	if endpoint != "" {
+		return S.requests[endpoint]
+	}
+
+	n := 0
+	for _, count := range S.requests {
+		n += count
+	}
+	return n
+}
+
+// fault counts the request and returns the first Fault that fires for it, nil if none does
+func (S *Server) fault(endpoint string) *Fault {
+	S.faultsMu.Lock()
+	defer S.faultsMu.Unlock()
+
+	S.requests[endpoint]++
+
+	var firing *Fault
+	for _, f := range S.faults {
+		if f.Endpoint != "" && f.Endpoint != endpoint {
+			continue
+		}
+
+		f.seen++
+		if firing != nil || f.seen <= f.After || f.Times > 0 && f.fired >= f.Times {
+			continue
+		}
+		f.fired++
+		firing = &f.Fault
+	}
+
+	if firing == nil {
+		return nil
+	}
+	injected := *firing
+	return &injected
+}
+
+// inject answers the request with the Fault instead of, or on top of, the normal response
+func (S *Server) inject(f *Fault, w http.ResponseWriter, r *http.Request, endpoint, key string) {
+	switch f.Kind {
+	case FaultRateLimit:
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+		}
+		writeError(w, http.StatusTooManyRequests, "Rate limited")
+	case FaultServerError:
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+	case FaultUnauthorized:
+		writeError(w, http.StatusUnauthorized, "Not authorized")
+	case FaultDelay:
+		// the server only notices the client giving up once the body of the request has been read
+		r.ParseForm()
+		timer := time.NewTimer(f.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			S.serve(w, r, endpoint, key)
+		case <-r.Context().Done():
+		}
+	case FaultTruncate:
+		rec := httptest.NewRecorder()
+		S.serve(rec, r, endpoint, key)
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		b := rec.Body.Bytes()
+		w.Write(b[:len(b)/2])
+	case FaultReset:
+		reset(w)
+	default:
+		S.serve(w, r, endpoint, key)
+	}
+}
+
+// reset closes the connection of the request with a TCP RST, falling back to aborting the response when the
+// connection can not be taken over
+func reset(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
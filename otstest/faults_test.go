@@ -0,0 +1,196 @@
+package otstest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestFaultStatusCodes(t *testing.T) {
+	tests := []struct {
+		kind otstest.FaultKind
+		want error
+	}{
+		{otstest.FaultRateLimit, onetimesecret.ErrRateLimited},
+		{otstest.FaultServerError, onetimesecret.ErrServerError},
+		{otstest.FaultUnauthorized, onetimesecret.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			srv, _, client := newTestServer(t)
+			defer srv.Close()
+			srv.InjectFault(otstest.Fault{Kind: tt.kind, Endpoint: "CreateSecret", Times: 1})
+
+			_, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+
+			// the fault fired once, and the endpoint behaves again after it
+			if _, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"}); err != nil {
+				t.Errorf("second request: %v", err)
+			}
+			if n := srv.Requests("CreateSecret"); n != 2 {
+				t.Errorf("Requests = %d, want 2", n)
+			}
+		})
+	}
+}
+
+func TestFaultRateLimitRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		want       string
+	}{
+		{"none", 0, ""},
+		{"whole seconds", 2 * time.Second, "2"},
+		{"rounded up", 1500 * time.Millisecond, "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := otstest.NewServer(nil)
+			defer srv.Close()
+			srv.InjectFault(otstest.Fault{Kind: otstest.FaultRateLimit, RetryAfter: tt.retryAfter})
+
+			resp, err := srv.HTTPClient().Get(srv.URL + "/api/v1/status")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+			}
+			if got := resp.Header.Get("Retry-After"); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFaultDelay(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultDelay, Endpoint: "CreateSecret", Times: 1, Delay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.CreateSecretWithContext(ctx, &onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+
+	// a delay shorter than the patience of the client only slows the request down
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultDelay, Endpoint: "Status", Delay: 10 * time.Millisecond})
+	if _, err := client.Status(); err != nil {
+		t.Errorf("delayed Status: %v", err)
+	}
+}
+
+func TestFaultTruncate(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultTruncate, Endpoint: "CreateSecret"})
+
+	_, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err == nil {
+		t.Fatal("CreateSecret decoded a truncated response")
+	}
+	var apiErr *onetimesecret.APIError
+	if errors.As(err, &apiErr) {
+		t.Errorf("err = %v, want a decoding error rather than an *APIError", err)
+	}
+
+	// the request took effect, so the secret exists even though its keys were lost
+	recent, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*recent) != 1 {
+		t.Errorf("%d recent secrets, want 1", len(*recent))
+	}
+}
+
+func TestFaultReset(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultReset, Endpoint: "CreateSecret", Times: 1})
+
+	_, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err == nil {
+		t.Fatal("CreateSecret succeeded through a reset connection")
+	}
+	var apiErr *onetimesecret.APIError
+	if errors.As(err, &apiErr) {
+		t.Errorf("err = %v, want a transport error rather than an *APIError", err)
+	}
+
+	// the request did not take effect
+	recent, err := client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*recent) != 0 {
+		t.Errorf("%d recent secrets, want 0", len(*recent))
+	}
+}
+
+func TestFaultAfterAndTimes(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "Status", After: 2, Times: 2})
+
+	var got []bool
+	for i := 0; i < 6; i++ {
+		_, err := client.Status()
+		got = append(got, err != nil)
+	}
+	want := []bool{false, false, true, true, false, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d failed = %v, want %v", i+1, got[i], want[i])
+		}
+	}
+
+	// other endpoints are not affected, and every request is counted
+	if _, err := client.Authcheck(); err != nil {
+		t.Errorf("Authcheck: %v", err)
+	}
+	if n := srv.Requests("Status"); n != 6 {
+		t.Errorf("Requests(Status) = %d, want 6", n)
+	}
+	if n := srv.Requests(""); n != 7 {
+		t.Errorf("Requests() = %d, want 7", n)
+	}
+
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError})
+	srv.ClearFaults()
+	if _, err := client.Status(); err != nil {
+		t.Errorf("Status after ClearFaults: %v", err)
+	}
+}
+
+func TestFaultFirstInjectedWins(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultUnauthorized, Times: 1})
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Times: 2})
+
+	if _, err := client.Status(); !errors.Is(err, onetimesecret.ErrUnauthorized) {
+		t.Errorf("first request: err = %v, want ErrUnauthorized", err)
+	}
+	// the second Fault was shadowed by the first, so it still fires twice
+	for i := 2; i <= 3; i++ {
+		if _, err := client.Status(); !errors.Is(err, onetimesecret.ErrServerError) {
+			t.Errorf("request %d: err = %v, want ErrServerError", i, err)
+		}
+	}
+	if _, err := client.Status(); err != nil {
+		t.Errorf("fourth request: %v", err)
+	}
+}
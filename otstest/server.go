@@ -0,0 +1,503 @@
+// Package otstest provides an in-process fake of the https://onetimesecret.com v1 and v2 APIs, so that code using
+// the onetimesecret Client can be tested hermetically.
+//
+// The fake keeps its secrets in memory and has the one-time semantics of the service: a secret can be retrieved once,
+// after which only its metadata remains, and secrets expire when their TTL runs out.
+package otstest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+const (
+	// DefaultTTL is the TTL of secrets created without one
+	DefaultTTL = 7 * 24 * time.Hour
+
+	// AnonymousCustID is the custid of secrets created without credentials
+	AnonymousCustID = "anon"
+
+	// apiPrefix is the path every v1 endpoint is served below
+	apiPrefix = "/api/v1/"
+)
+
+// ServerOptions are provided to adjust the behaviour of the fake Server
+type ServerOptions struct {
+	// Now drives the TTL expiry of secrets. It defaults to time.Now; pass the Now method of a Clock to control it.
+	Now func() time.Time
+
+	// Accounts maps the usernames that may authenticate to their API tokens.
+	Accounts map[string]string
+}
+
+// Server is a fake of the https://onetimesecret.com v1 and v2 APIs served by an httptest.Server
+//
+// Both versions are served side by side, from the same secrets: a secret created through one can be read through
+// the other.
+//
+// Requests without credentials are served anonymously, as the service does. Requests with credentials that do not
+// match an account fail with 401 Unauthorized. Misbehaviour can be scripted with InjectFault.
+type Server struct {
+	// URL is the base URL of the fake, e.g. "http://127.0.0.1:54321"
+	URL string
+
+	srv *httptest.Server
+	now func() time.Time
+
+	mu            sync.Mutex
+	accounts      map[string]string
+	byMetadataKey map[string]*secret
+	bySecretKey   map[string]*secret
+
+	faultsMu sync.Mutex
+	faults   []*fault
+	requests map[string]int
+}
+
+// secret is a secret stored by the fake, along with its metadata
+type secret struct {
+	meta       onetimesecret.Metadata
+	value      string
+	passphrase string
+}
+
+// NewServer will start a new fake Server
+//
+// Variables:
+//     opts (*ServerOptions): A pointer to a ServerOptions struct, nil for the defaults
+//
+// Returns:
+//     (*Server): A pointer to a new, started instance of Server. Close it when done.
+func NewServer(opts *ServerOptions) *Server {
+	if opts == nil {
+		opts = &ServerOptions{}
+	}
+
+	S := &Server{
+		now:           opts.Now,
+		accounts:      make(map[string]string),
+		byMetadataKey: make(map[string]*secret),
+		bySecretKey:   make(map[string]*secret),
+		requests:      make(map[string]int),
+	}
+	if S.now == nil {
+		S.now = time.Now
+	}
+	for username, apiToken := range opts.Accounts {
+		S.accounts[username] = apiToken
+	}
+
+	S.srv = httptest.NewServer(S)
+	S.URL = S.srv.URL
+	return S
+}
+
+// Close will shut the Server down, blocking until all outstanding requests have completed
+//
+// Variables:
+//     None
+//
+// Returns:
+//     None
+func (S *Server) Close() {
+	S.srv.Close()
+}
+
+// HTTPClient will return an HTTP client configured to talk to the Server
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*http.Client): The HTTP client
+func (S *Server) HTTPClient() *http.Client {
+	return S.srv.Client()
+}
+
+// AddAccount will add an account that requests may authenticate as, or replace the API token of an existing one
+//
+// Variables:
+//     username (string): The username of the account
+//     apiToken (string): The API token of the account
+//
+// Returns:
+//     None
+func (S *Server) AddAccount(username, apiToken string) {
+	S.mu.Lock()
+	defer S.mu.Unlock()
+	S.accounts[username] = apiToken
+}
+
+// NewClient will generate a new onetimesecret Client that talks to the Server
+//
+// Variables:
+//     opts (*onetimesecret.ClientOptions): A pointer to the options of the Client, nil for an anonymous Client.
+//                                          OneTimeSecretURL and Region are overridden, APIVersion defaults to
+//                                          v1 and HTTPClient defaults to the one of the Server.
+//
+// Returns:
+//     (*onetimesecret.Client): A pointer to a new instance of Client, nil if an error occurred
+//     (error):                 An error if the options are invalid, nil otherwise
+func (S *Server) NewClient(opts *onetimesecret.ClientOptions) (*onetimesecret.Client, error) {
+	var o onetimesecret.ClientOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	o.OneTimeSecretURL = S.URL
+	o.Region = ""
+	if o.APIVersion == "" {
+		o.APIVersion = onetimesecret.APIVersion1
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = S.HTTPClient()
+	}
+	return onetimesecret.NewWithOptions(&o)
+}
+
+// Client will generate a new onetimesecret Client that talks to the Server, like NewClient, failing the test if the
+// options are invalid
+//
+// Variables:
+//     t (testing.TB):                      The test the Client is used by
+//     opts (*onetimesecret.ClientOptions): A pointer to the options of the Client, nil for an anonymous Client
+//
+// Returns:
+//     (*onetimesecret.Client): A pointer to a new instance of Client
+func (S *Server) Client(t testing.TB, opts *onetimesecret.ClientOptions) *onetimesecret.Client {
+	t.Helper()
+	client, err := S.NewClient(opts)
+	if err != nil {
+		t.Fatalf("otstest: %v", err)
+	}
+	return client
+}
+
+// ServeHTTP implements http.Handler, injecting any matching Fault and routing the request to the fake endpoint
+func (S *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint, key := route(r)
+	f := S.fault(endpoint)
+	if isV2(r) && endpoint != "" {
+		if err := readV2Form(r, endpoint); err != nil {
+			writeV2Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if f != nil {
+		S.inject(f, w, r, endpoint, key)
+		return
+	}
+	S.serve(w, r, endpoint, key)
+}
+
+// route names the endpoint of the request after the Client call it serves, returning the secret or metadata key in
+// the path if it has one, and "" for a path that is no endpoint
+func route(r *http.Request) (string, string) {
+	if isV2(r) {
+		return routeV2(r)
+	}
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		return "", ""
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	parts := strings.Split(path, "/")
+	switch {
+	case r.Method == http.MethodGet && path == "status":
+		return "Status", ""
+	case r.Method == http.MethodGet && path == "authcheck":
+		return "Authcheck", ""
+	case r.Method == http.MethodPost && path == "share":
+		return "CreateSecret", ""
+	case r.Method == http.MethodPost && path == "generate":
+		return "GenerateSecret", ""
+	case r.Method == http.MethodGet && path == "private/recent":
+		return "RetrieveRecentMetadata", ""
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[0] == "secret":
+		return "RetrieveSecret", parts[1]
+	case len(parts) == 2 && parts[0] == "private":
+		return "RetrieveMetadata", parts[1]
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "private" && parts[2] == "burn":
+		return "BurnSecret", parts[1]
+	}
+	return "", ""
+}
+
+// serve answers the request the way the service would
+func (S *Server) serve(w http.ResponseWriter, r *http.Request, endpoint, key string) {
+	if isV2(r) {
+		S.serveV2(w, r, endpoint, key)
+		return
+	}
+	S.serveV1(w, r, endpoint, key)
+}
+
+// serveV1 answers the request in the form of the v1 API
+func (S *Server) serveV1(w http.ResponseWriter, r *http.Request, endpoint, key string) {
+	if endpoint == "" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	S.mu.Lock()
+	defer S.mu.Unlock()
+
+	custid, ok := S.authenticate(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Not authorized")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "Malformed request")
+		return
+	}
+
+	switch endpoint {
+	case "Status":
+		writeJSON(w, http.StatusOK, onetimesecret.StatusResponse{Status: "nominal", Locale: "en"})
+	case "Authcheck":
+		S.authcheck(w, custid)
+	case "CreateSecret":
+		S.share(w, r, custid)
+	case "GenerateSecret":
+		S.generate(w, r, custid)
+	case "RetrieveRecentMetadata":
+		S.recent(w, custid)
+	case "RetrieveSecret":
+		S.retrieveSecret(w, r, key)
+	case "RetrieveMetadata":
+		S.retrieveMetadata(w, key)
+	case "BurnSecret":
+		S.burn(w, key)
+	}
+}
+
+// authenticate returns the custid of the request, AnonymousCustID if it has no credentials, and false if its
+// credentials match no account
+func (S *Server) authenticate(r *http.Request) (string, bool) {
+	username, apiToken, ok := r.BasicAuth()
+	if !ok {
+		return AnonymousCustID, true
+	}
+	if token, found := S.accounts[username]; !found || token == "" || token != apiToken {
+		return "", false
+	}
+	return username, true
+}
+
+func (S *Server) authcheck(w http.ResponseWriter, custid string) {
+	if custid == AnonymousCustID {
+		writeError(w, http.StatusUnauthorized, "Not authorized")
+		return
+	}
+	writeJSON(w, http.StatusOK, onetimesecret.AuthcheckResponse{CustID: custid, Role: "customer"})
+}
+
+func (S *Server) share(w http.ResponseWriter, r *http.Request, custid string) {
+	value := r.Form.Get("secret")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, "You did not provide anything to share")
+		return
+	}
+
+	s, ok := S.store(w, r, custid, value)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, S.view(s))
+}
+
+func (S *Server) generate(w http.ResponseWriter, r *http.Request, custid string) {
+	s, ok := S.store(w, r, custid, newKey()[:12])
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, onetimesecret.GenerateSecretResponse{Metadata: S.view(s), Value: s.value})
+}
+
+// store creates a secret from the ttl, passphrase and recipient form parameters, writing an error response and
+// returning false if they are invalid
+func (S *Server) store(w http.ResponseWriter, r *http.Request, custid, value string) (*secret, bool) {
+	ttl := DefaultTTL
+	if v := r.Form.Get("ttl"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "Invalid TTL")
+			return nil, false
+		}
+		ttl = time.Duration(n) * time.Second
+	}
+
+	var recipient []string
+	for _, addr := range r.Form["recipient"] {
+		recipient = append(recipient, obfuscate(addr))
+	}
+
+	now := S.now()
+	s := &secret{
+		meta: onetimesecret.Metadata{
+			CustID:             custid,
+			MetadataKey:        newKey(),
+			SecretKey:          newKey(),
+			TTL:                ttl,
+			Recipient:          recipient,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+			PassphraseRequired: r.Form.Get("passphrase") != "",
+			State:              onetimesecret.StateNew,
+		},
+		value:      value,
+		passphrase: r.Form.Get("passphrase"),
+	}
+	S.byMetadataKey[s.meta.MetadataKey] = s
+	S.bySecretKey[s.meta.SecretKey] = s
+	return s, true
+}
+
+func (S *Server) retrieveSecret(w http.ResponseWriter, r *http.Request, key string) {
+	s, ok := S.bySecretKey[key]
+	if !ok || !S.refresh(s) || s.meta.State.Final() {
+		writeError(w, http.StatusNotFound, "Unknown secret")
+		return
+	}
+
+	switch passphrase := r.Form.Get("passphrase"); {
+	case s.passphrase != "" && passphrase == "":
+		writeError(w, http.StatusNotFound, "A passphrase is required to view this secret")
+		return
+	case s.passphrase != passphrase:
+		writeError(w, http.StatusNotFound, "Incorrect passphrase")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, onetimesecret.RetrieveSecretResponse{SecretKey: key, SecretValue: s.value})
+	S.finish(s, onetimesecret.StateReceived, S.now())
+}
+
+func (S *Server) retrieveMetadata(w http.ResponseWriter, key string) {
+	s, ok := S.byMetadataKey[key]
+	if !ok || !S.refresh(s) {
+		writeError(w, http.StatusNotFound, "Unknown secret")
+		return
+	}
+	writeJSON(w, http.StatusOK, S.view(s))
+}
+
+func (S *Server) burn(w http.ResponseWriter, key string) {
+	s, ok := S.byMetadataKey[key]
+	if !ok || !S.refresh(s) {
+		writeError(w, http.StatusNotFound, "Unknown secret")
+		return
+	}
+
+	// burning a secret that is already gone leaves it as it is, so that a retried burn succeeds
+	if !s.meta.State.Final() {
+		S.finish(s, onetimesecret.StateBurned, S.now())
+	}
+	writeJSON(w, http.StatusOK, S.view(s))
+}
+
+func (S *Server) recent(w http.ResponseWriter, custid string) {
+	if custid == AnonymousCustID {
+		writeError(w, http.StatusUnauthorized, "Not authorized")
+		return
+	}
+
+	var list []*secret
+	for _, s := range S.byMetadataKey {
+		if s.meta.CustID == custid && S.refresh(s) {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].meta.CreatedAt.Equal(list[j].meta.CreatedAt) {
+			return list[i].meta.CreatedAt.After(list[j].meta.CreatedAt)
+		}
+		return list[i].meta.MetadataKey < list[j].meta.MetadataKey
+	})
+
+	resp := make(onetimesecret.RetrieveRecentMetadataResponse, 0, len(list))
+	for _, s := range list {
+		resp = append(resp, S.view(s))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// refresh expires the secret if its TTL has run out and forgets it entirely once its metadata has expired too,
+// reporting false in that case. Metadata lives twice as long as its secret, as it does on the service.
+func (S *Server) refresh(s *secret) bool {
+	now := S.now()
+	if !now.Before(s.meta.CreatedAt.Add(2 * s.meta.TTL)) {
+		delete(S.byMetadataKey, s.meta.MetadataKey)
+		delete(S.bySecretKey, s.meta.SecretKey)
+		return false
+	}
+	if !s.meta.State.Final() && !now.Before(s.meta.CreatedAt.Add(s.meta.TTL)) {
+		S.finish(s, onetimesecret.StateExpired, s.meta.CreatedAt.Add(s.meta.TTL))
+	}
+	return true
+}
+
+// finish moves the secret into a final state, after which its value can never be retrieved
+func (S *Server) finish(s *secret, state onetimesecret.State, at time.Time) {
+	s.meta.State = state
+	s.meta.UpdatedAt = at
+	if state == onetimesecret.StateReceived {
+		s.meta.Received = at
+	}
+	s.value = ""
+	delete(S.bySecretKey, s.meta.SecretKey)
+}
+
+// view returns the metadata of the secret as the service shows it, with the remaining TTLs filled in and the secret
+// key hidden once the secret is gone
+func (S *Server) view(s *secret) onetimesecret.Metadata {
+	now := S.now()
+	m := s.meta
+	m.MetadataTTL = s.meta.CreatedAt.Add(2 * s.meta.TTL).Sub(now)
+	if m.State.Final() {
+		m.SecretKey = ""
+	} else {
+		m.SecretTTL = s.meta.CreatedAt.Add(s.meta.TTL).Sub(now)
+	}
+	return m
+}
+
+// newKey returns a random key in the manner of the service's metadata and secret keys
+func newKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// obfuscate hides most of an email address, as the service does for recipients
+func obfuscate(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 1 {
+		return "*****"
+	}
+	return email[:1] + "*****" + email[i:]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,190 @@
+package otstest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// start is the time of the Clock of every test
+var start = time.Unix(1700000000, 0)
+
+// newTestServer returns a Server driven by a Clock, with the account alice, and a Client of alice
+func newTestServer(t *testing.T) (*otstest.Server, *otstest.Clock, *onetimesecret.Client) {
+	t.Helper()
+	clock := otstest.NewClock(start)
+	srv := otstest.NewServer(&otstest.ServerOptions{Now: clock.Now, Accounts: map[string]string{"alice": "token"}})
+	client, err := srv.NewClient(&onetimesecret.ClientOptions{
+		Credentials: &onetimesecret.Credentials{Username: "alice", APIToken: "token"},
+	})
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+	return srv, clock, client
+}
+
+func TestSecretIsRetrievedOnce(t *testing.T) {
+	srv, clock, client := newTestServer(t)
+	defer srv.Close()
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.State != onetimesecret.StateNew {
+		t.Errorf("State = %v, want %v", created.State, onetimesecret.StateNew)
+	}
+
+	clock.Advance(time.Minute)
+	retrieved, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retrieved.SecretValue != "s3cret" {
+		t.Errorf("SecretValue = %q, want %q", retrieved.SecretValue, "s3cret")
+	}
+
+	_, err = client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey})
+	if !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+		t.Errorf("second retrieval: err = %v, want ErrSecretNotFound", err)
+	}
+
+	metadata, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.State != onetimesecret.StateReceived {
+		t.Errorf("State = %v, want %v", metadata.State, onetimesecret.StateReceived)
+	}
+	if want := start.Add(time.Minute); !metadata.Received.Equal(want) {
+		t.Errorf("Received = %v, want %v", metadata.Received, want)
+	}
+	if metadata.SecretKey != "" {
+		t.Errorf("SecretKey = %q, want it hidden once received", metadata.SecretKey)
+	}
+}
+
+func TestSecretExpires(t *testing.T) {
+	srv, clock, client := newTestServer(t)
+	defer srv.Close()
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.SecretTTL != time.Hour || created.MetadataTTL != 2*time.Hour {
+		t.Errorf("SecretTTL, MetadataTTL = %v, %v, want %v, %v", created.SecretTTL, created.MetadataTTL, time.Hour, 2*time.Hour)
+	}
+
+	clock.Advance(59 * time.Minute)
+	metadata, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.State != onetimesecret.StateNew || metadata.SecretTTL != time.Minute {
+		t.Errorf("State, SecretTTL = %v, %v, want %v, %v", metadata.State, metadata.SecretTTL, onetimesecret.StateNew, time.Minute)
+	}
+
+	clock.Advance(time.Minute)
+	_, err = client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey})
+	if !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound", err)
+	}
+	metadata, err = client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.State != onetimesecret.StateExpired {
+		t.Errorf("State = %v, want %v", metadata.State, onetimesecret.StateExpired)
+	}
+
+	// metadata lives twice as long as its secret
+	clock.Advance(time.Hour)
+	_, err = client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey})
+	if !errors.Is(err, onetimesecret.ErrSecretNotFound) {
+		t.Errorf("err = %v, want ErrSecretNotFound once the metadata expired", err)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", Passphrase: "pw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.PassphraseRequired {
+		t.Error("PassphraseRequired = false, want true")
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{"missing", ""},
+		{"wrong", "not pw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey, Passphrase: tt.passphrase})
+			if !errors.Is(err, onetimesecret.ErrPassphraseRequired) {
+				t.Errorf("err = %v, want ErrPassphraseRequired", err)
+			}
+		})
+	}
+
+	// failed attempts leave the secret in place
+	retrieved, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey, Passphrase: "pw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retrieved.SecretValue != "s3cret" {
+		t.Errorf("SecretValue = %q, want %q", retrieved.SecretValue, "s3cret")
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	srv, _, client := newTestServer(t)
+	defer srv.Close()
+
+	if resp, err := client.Authcheck(); err != nil {
+		t.Fatal(err)
+	} else if resp.CustID != "alice" {
+		t.Errorf("CustID = %q, want %q", resp.CustID, "alice")
+	}
+
+	tests := []struct {
+		name  string
+		creds onetimesecret.Credentials
+	}{
+		{"wrong token", onetimesecret.Credentials{Username: "alice", APIToken: "nope"}},
+		{"unknown user", onetimesecret.Credentials{Username: "mallory", APIToken: "token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds := tt.creds
+			bad := srv.Client(t, &onetimesecret.ClientOptions{Credentials: &creds})
+			if _, err := bad.Authcheck(); !errors.Is(err, onetimesecret.ErrUnauthorized) {
+				t.Errorf("Authcheck: err = %v, want ErrUnauthorized", err)
+			}
+			if _, err := bad.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"}); !errors.Is(err, onetimesecret.ErrUnauthorized) {
+				t.Errorf("CreateSecret: err = %v, want ErrUnauthorized", err)
+			}
+		})
+	}
+
+	// without credentials, secrets are shared anonymously
+	anon := srv.Client(t, nil)
+	created, err := anon.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.CustID != otstest.AnonymousCustID {
+		t.Errorf("CustID = %q, want %q", created.CustID, otstest.AnonymousCustID)
+	}
+}
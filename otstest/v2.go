@@ -0,0 +1,186 @@
+package otstest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// apiV2Prefix is the path every v2 endpoint is served below
+const apiV2Prefix = "/api/v2/"
+
+// isV2 reports whether the request is to the v2 API
+func isV2(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, apiV2Prefix)
+}
+
+// routeV2 names the endpoint of a v2 request, as route does for v1 requests
+func routeV2(r *http.Request) (string, string) {
+	path := strings.TrimPrefix(r.URL.Path, apiV2Prefix)
+	parts := strings.Split(path, "/")
+	switch {
+	case r.Method == http.MethodGet && path == "status":
+		return "Status", ""
+	case r.Method == http.MethodGet && path == "authcheck":
+		return "Authcheck", ""
+	case r.Method == http.MethodPost && path == "secret/conceal":
+		return "CreateSecret", ""
+	case r.Method == http.MethodPost && path == "secret/generate":
+		return "GenerateSecret", ""
+	case r.Method == http.MethodGet && path == "private/recent":
+		return "RetrieveRecentMetadata", ""
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "secret" && parts[2] == "reveal":
+		return "RetrieveSecret", parts[1]
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "private":
+		return "RetrieveMetadata", parts[1]
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "private" && parts[2] == "burn":
+		return "BurnSecret", parts[1]
+	}
+	return "", ""
+}
+
+// readV2Form reads the JSON body of a v2 request into the form parameters the v1 endpoints are served from
+func readV2Form(r *http.Request, endpoint string) error {
+	form := url.Values{}
+	body := map[string]interface{}{}
+	if r.Method == http.MethodPost {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if len(b) != 0 {
+			if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				return errors.New("Expected a JSON body")
+			}
+			if err := json.Unmarshal(b, &body); err != nil {
+				return errors.New("Malformed JSON body")
+			}
+		}
+	}
+
+	switch endpoint {
+	case "CreateSecret", "GenerateSecret":
+		secret, ok := body["secret"].(map[string]interface{})
+		if !ok {
+			return errors.New("Expected a secret object")
+		}
+		for k, v := range secret {
+			switch v := v.(type) {
+			case string:
+				form.Set(k, v)
+			case float64:
+				form.Set(k, strconv.FormatFloat(v, 'f', -1, 64))
+			case []interface{}:
+				for _, item := range v {
+					s, ok := item.(string)
+					if !ok {
+						return errors.New("Expected a list of strings for " + k)
+					}
+					form.Add(k, s)
+				}
+			default:
+				return errors.New("Unexpected value for " + k)
+			}
+		}
+	case "RetrieveSecret", "BurnSecret":
+		// the service asks for a confirmation before revealing or burning a secret
+		if body["continue"] != true {
+			return errors.New("Expected continue to be true")
+		}
+		if passphrase, ok := body["passphrase"].(string); ok {
+			form.Set("passphrase", passphrase)
+		}
+	}
+
+	r.Form = form
+	r.PostForm = form
+	return nil
+}
+
+// serveV2 answers a v2 request by serving it as v1 and wrapping the response in the record and details envelope of
+// the v2 API
+func (S *Server) serveV2(w http.ResponseWriter, r *http.Request, endpoint, key string) {
+	rec := httptest.NewRecorder()
+	S.serveV1(rec, r, endpoint, key)
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+
+	var v1 interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &v1); err != nil {
+		writeV2Error(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if rec.Code != http.StatusOK {
+		m, _ := v1.(map[string]interface{})
+		message, _ := m["message"].(string)
+		writeV2Error(w, rec.Code, message)
+		return
+	}
+	writeJSON(w, http.StatusOK, v2Response(endpoint, key, v1))
+}
+
+// v2Response converts the v1 response of an endpoint into its v2 form
+func v2Response(endpoint, key string, v1 interface{}) interface{} {
+	m, _ := v1.(map[string]interface{})
+	details := map[string]interface{}{}
+
+	switch endpoint {
+	case "Status":
+		return v1
+	case "Authcheck":
+		return map[string]interface{}{"record": map[string]interface{}{"identifier": m["custid"], "role": m["role"]}, "details": details}
+	case "CreateSecret", "GenerateSecret":
+		secret := map[string]interface{}{
+			"key":            m["secret_key"],
+			"secret_ttl":     m["secret_ttl"],
+			"has_passphrase": m["passphrase_required"],
+		}
+		if endpoint == "GenerateSecret" {
+			secret["value"] = m["value"]
+		}
+		metadata := v2Record(m)
+		for _, k := range []string{"secret_key", "secret_ttl", "passphrase_required", "value"} {
+			delete(metadata, k)
+		}
+		return map[string]interface{}{"record": map[string]interface{}{"metadata": metadata, "secret": secret}, "details": details}
+	case "RetrieveSecret":
+		record := map[string]interface{}{"identifier": key, "secret_value": m["value"]}
+		return map[string]interface{}{"record": record, "details": map[string]interface{}{"show_secret": true}}
+	case "RetrieveRecentMetadata":
+		list, _ := v1.([]interface{})
+		records := make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			m, _ := item.(map[string]interface{})
+			records = append(records, v2Record(m))
+		}
+		return map[string]interface{}{"records": records, "details": details}
+	}
+	return map[string]interface{}{"record": v2Record(m), "details": details}
+}
+
+// v2Record renames the fields of a v1 metadata response to their v2 names
+func v2Record(m map[string]interface{}) map[string]interface{} {
+	record := map[string]interface{}{}
+	for k, v := range m {
+		switch k {
+		case "metadata_key":
+			record["key"] = v
+		case "recipient":
+			record["recipients"] = v
+		default:
+			record[k] = v
+		}
+	}
+	return record
+}
+
+// writeV2Error writes the error envelope of the v2 API
+func writeV2Error(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"success": false, "message": message})
+}
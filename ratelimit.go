@@ -0,0 +1,152 @@
+package onetimesecret
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that gates every request a Client sends to the service
+//
+// A single RateLimiter is safe for concurrent use and may be shared by several Clients that use the same account.
+// It adapts to the service: every 429 Too Many Requests response halves the rate (down to a tenth of the configured
+// rate) and pauses the bucket for the Retry-After delay, while every successful response recovers a tenth of the
+// configured rate until it is reached again.
+//
+// The zero value is not usable: create a RateLimiter with NewRateLimiter.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  float64
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter will generate a new RateLimiter
+//
+// Variables:
+//     requestsPerSecond (float64): The sustained number of requests per second to allow, greater than 0
+//     burst (int):                 The number of requests that may be sent at once after a quiet period, at least 1
+//
+// Returns:
+//     (*RateLimiter): A pointer to a new instance of RateLimiter, nil if an error occurred
+//     (error):        An error if requestsPerSecond is not a positive finite number or burst is below 1, nil otherwise
+func NewRateLimiter(requestsPerSecond float64, burst int) (*RateLimiter, error) {
+	if !(requestsPerSecond > 0) || math.IsInf(requestsPerSecond, 1) {
+		return nil, fmt.Errorf("requestsPerSecond must be a positive number, got %v", requestsPerSecond)
+	}
+	if burst < 1 {
+		return nil, fmt.Errorf("burst must be at least 1, got %d", burst)
+	}
+	return &RateLimiter{
+		limit:  requestsPerSecond,
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// Rate will return the number of requests per second currently allowed, which drops below the configured rate after 429 responses
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (float64): The current requests per second
+func (L *RateLimiter) Rate() float64 {
+	L.mu.Lock()
+	defer L.mu.Unlock()
+	return L.rate
+}
+
+// Wait will block until a request may be sent or ctx is done
+//
+// Variables:
+//     ctx (context.Context): The context that bounds how long to wait
+//
+// Returns:
+//     (error): ctx.Err() if ctx was done before a request was allowed, nil otherwise
+func (L *RateLimiter) Wait(ctx context.Context) error {
+	wait := L.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		L.mu.Lock()
+		L.tokens++
+		L.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// reserve takes a token, possibly going into debt, and returns how long the caller has to wait before using it
+func (L *RateLimiter) reserve() time.Duration {
+	L.mu.Lock()
+	defer L.mu.Unlock()
+
+	now := time.Now()
+	L.refill(now)
+	L.tokens--
+
+	var wait time.Duration
+	if L.last.After(now) {
+		wait = L.last.Sub(now)
+	}
+	if L.tokens < 0 && L.rate > 0 {
+		wait += time.Duration(-L.tokens / L.rate * float64(time.Second))
+	}
+	return wait
+}
+
+// refill adds the tokens accrued since the last refill; the caller must hold L.mu
+func (L *RateLimiter) refill(now time.Time) {
+	if !now.After(L.last) {
+		return
+	}
+	L.tokens += now.Sub(L.last).Seconds() * L.rate
+	if L.tokens > L.burst {
+		L.tokens = L.burst
+	}
+	L.last = now
+}
+
+// observe adapts the rate to the status code of a response
+func (L *RateLimiter) observe(httpResp *http.Response) {
+	L.mu.Lock()
+	defer L.mu.Unlock()
+
+	now := time.Now()
+	L.refill(now)
+
+	if httpResp.StatusCode != http.StatusTooManyRequests {
+		if L.rate < L.limit {
+			L.rate += L.limit / 10
+			if L.rate > L.limit {
+				L.rate = L.limit
+			}
+		}
+		return
+	}
+
+	L.rate /= 2
+	if L.rate < L.limit/10 {
+		L.rate = L.limit / 10
+	}
+	if L.tokens > 0 {
+		L.tokens = 0
+	}
+	if d, ok := retryAfter(httpResp); ok && now.Add(d).After(L.last) {
+		L.last = now.Add(d)
+	}
+}
@@ -0,0 +1,204 @@
+package onetimesecret_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestNewRateLimiterRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		burst             int
+	}{
+		{"zero rate", 0, 1},
+		{"negative rate", -1, 1},
+		{"NaN rate", math.NaN(), 1},
+		{"infinite rate", math.Inf(1), 1},
+		{"zero burst", 1, 0},
+		{"negative burst", 1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := onetimesecret.NewRateLimiter(tt.requestsPerSecond, tt.burst)
+			if err == nil || limiter != nil {
+				t.Errorf("NewRateLimiter(%v, %d) = %v, %v, want an error", tt.requestsPerSecond, tt.burst, limiter, err)
+			}
+		})
+	}
+
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	if _, err := srv.NewClient(&onetimesecret.ClientOptions{RateLimiter: &onetimesecret.RateLimiter{}}); err == nil {
+		t.Error("NewWithOptions accepted a zero RateLimiter")
+	}
+}
+
+// newRateLimiter returns a RateLimiter, failing the test if the values are invalid
+func newRateLimiter(t *testing.T, requestsPerSecond float64, burst int) *onetimesecret.RateLimiter {
+	t.Helper()
+	limiter, err := onetimesecret.NewRateLimiter(requestsPerSecond, burst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return limiter
+}
+
+func TestRateLimiterConcurrentCallers(t *testing.T) {
+	const (
+		rate    = 50
+		burst   = 5
+		callers = 30
+	)
+	limiter := newRateLimiter(t, rate, burst)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the burst goes at once and the rest at the rate
+	want := time.Duration(float64(callers-burst) / rate * float64(time.Second))
+	if d := time.Since(start); d < want*9/10 || d > want*3 {
+		t.Errorf("%d callers took %v, want about %v", callers, d, want)
+	}
+}
+
+func TestRateLimiterWaitIsCancelled(t *testing.T) {
+	limiter := newRateLimiter(t, 0.001, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRateLimiterAdaptsTo429(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	limiter := newRateLimiter(t, 100, 100)
+	client := srv.Client(t, &onetimesecret.ClientOptions{RateLimiter: limiter})
+
+	// every 429 halves the rate, down to a tenth of the configured one
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultRateLimit, Endpoint: "Status", Times: 5})
+	for _, want := range []float64{50, 25, 12.5, 10, 10} {
+		if _, err := client.Status(); !errors.Is(err, onetimesecret.ErrRateLimited) {
+			t.Fatalf("err = %v, want ErrRateLimited", err)
+		}
+		if got := limiter.Rate(); got != want {
+			t.Errorf("Rate() = %v, want %v", got, want)
+		}
+	}
+
+	// every success recovers a tenth of the configured rate, up to the configured rate
+	for _, want := range []float64{20, 30, 40, 50, 60, 70, 80, 90, 100, 100} {
+		if _, err := client.Status(); err != nil {
+			t.Fatal(err)
+		}
+		if got := limiter.Rate(); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Rate() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRateLimiterHonoursRetryAfter(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	limiter := newRateLimiter(t, 100, 100)
+	client := srv.Client(t, &onetimesecret.ClientOptions{RateLimiter: limiter})
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultRateLimit, Endpoint: "Status", Times: 1, RetryAfter: time.Second})
+
+	if _, err := client.Status(); !errors.Is(err, onetimesecret.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if got := limiter.Rate(); got != 50 {
+		t.Errorf("Rate() = %v, want 50", got)
+	}
+
+	// the bucket is paused for the Retry-After delay
+	start := time.Now()
+	if _, err := client.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 900*time.Millisecond {
+		t.Errorf("the request after a Retry-After of 1s was sent after %v", d)
+	}
+}
+
+func TestRateLimiterGatesEveryCall(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	calls := map[string]func(context.Context, *onetimesecret.Client) error{
+		"CreateSecret": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.CreateSecretWithContext(ctx, &onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+			return err
+		},
+		"GenerateSecret": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.GenerateSecretWithContext(ctx, &onetimesecret.GenerateSecretRequest{})
+			return err
+		},
+		"RetrieveSecret": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.RetrieveSecretWithContext(ctx, &onetimesecret.RetrieveSecretRequest{SecretKey: key})
+			return err
+		},
+		"RetrieveMetadata": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.RetrieveMetadataWithContext(ctx, &onetimesecret.RetrieveMetadataRequest{MetadataKey: key})
+			return err
+		},
+		"BurnSecret": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.BurnSecretWithContext(ctx, &onetimesecret.BurnSecretRequest{MetadataKey: key})
+			return err
+		},
+		"RetrieveRecentMetadata": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.RetrieveRecentMetadataWithContext(ctx, &onetimesecret.RetrieveRecentMetadataRequest{})
+			return err
+		},
+		"Status": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.StatusWithContext(ctx)
+			return err
+		},
+		"Authcheck": func(ctx context.Context, c *onetimesecret.Client) error {
+			_, err := c.AuthcheckWithContext(ctx)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			srv := newTestServer()
+			defer srv.Close()
+			limiter := newRateLimiter(t, 0.001, 1)
+			client := newTestClient(t, srv, &onetimesecret.ClientOptions{RateLimiter: limiter})
+
+			// with the only token taken, the call must wait for the next one, which comes long after the deadline
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := call(ctx, client); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("err = %v, want context.DeadlineExceeded", err)
+			}
+			if n := srv.Requests(""); n != 0 {
+				t.Errorf("%d requests reached the server, want 0", n)
+			}
+		})
+	}
+}
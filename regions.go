@@ -0,0 +1,127 @@
+package onetimesecret
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultURL is the service a Client talks to when created with New
+const DefaultURL = "https://onetimesecret.com"
+
+// Region names a hosted onetimesecret region, or a custom domain registered with RegisterRegion
+type Region string
+
+// Hosted regions of https://onetimesecret.com
+const (
+	RegionUS Region = "us"
+	RegionEU Region = "eu"
+	RegionCA Region = "ca"
+	RegionNZ Region = "nz"
+)
+
+var (
+	regionsMu sync.RWMutex
+	regions   = map[Region]string{
+		RegionUS: "https://us.onetimesecret.com",
+		RegionEU: "https://eu.onetimesecret.com",
+		RegionCA: "https://ca.onetimesecret.com",
+		RegionNZ: "https://nz.onetimesecret.com",
+	}
+)
+
+// RegisterRegion will add a region, such as a custom domain or self-hosted deployment, to the registry or replace the
+// URL of an existing one
+//
+// Variables:
+//     region (Region): The name of the region
+//     rawURL (string): The base URL of the service in that region
+//
+// Returns:
+//     (error): An error if the URL is invalid, nil otherwise
+func RegisterRegion(region Region, rawURL string) error {
+	u, err := NormalizeURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	regionsMu.Lock()
+	defer regionsMu.Unlock()
+	regions[region] = u
+	return nil
+}
+
+// Regions will list the names of all registered regions in alphabetical order
+//
+// Variables:
+//     None
+//
+// Returns:
+//     ([]Region): The registered regions
+func Regions() []Region {
+	regionsMu.RLock()
+	defer regionsMu.RUnlock()
+
+	list := make([]Region, 0, len(regions))
+	for r := range regions {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// URL will look up the base URL of the region in the registry
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (string): The base URL of the region, without a trailing slash
+//     (error):  An error if the region is not registered, nil otherwise
+func (R Region) URL() (string, error) {
+	regionsMu.RLock()
+	defer regionsMu.RUnlock()
+
+	u, ok := regions[R]
+	if !ok {
+		return "", fmt.Errorf("unknown region %q", string(R))
+	}
+	return u, nil
+}
+
+// NormalizeURL will validate the base URL of a service and strip any trailing slashes from it
+//
+// The URL must be absolute, use the http or https scheme and have no query or fragment. It may have a path, for
+// deployments served below the root of their domain.
+//
+// Variables:
+//     rawURL (string): The URL to validate
+//
+// Returns:
+//     (string): The normalized URL
+//     (error):  An error if the URL is invalid, nil otherwise
+func NormalizeURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", fmt.Errorf("service URL must not be \"\"")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid service URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid service URL %q: scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid service URL %q: missing host", rawURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("invalid service URL %q: must not have user info, a query or a fragment", rawURL)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
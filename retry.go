@@ -0,0 +1,119 @@
+package onetimesecret
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how the Client retries idempotent calls after transient failures
+//
+// Only RetrieveMetadata, RetrieveRecentMetadata, BurnSecret, Status and Authcheck are ever retried. CreateSecret,
+// GenerateSecret and RetrieveSecret are never retried because repeating them could create duplicate secrets or
+// consume a secret twice.
+//
+//  Attributes
+//
+//    MaxAttempts: the total number of attempts, including the first one. Values below 2 disable retries.
+//    InitialBackoff: the upper bound of the delay before the first retry; it doubles with every further attempt.
+//    MaxBackoff: the cap on the upper bound of the delay between attempts.
+//
+// The actual delay is chosen at random between zero and the current upper bound (full jitter). When the service
+// responds with 429 Too Many Requests or 503 Service Unavailable and a Retry-After header, that delay is used instead,
+// capped at MaxBackoff if it is set.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy will generate a RetryPolicy with sensible defaults
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*RetryPolicy): A pointer to a RetryPolicy allowing 3 attempts with backoff between 250ms and 5s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+var (
+	jitterMu sync.Mutex
+	jitter   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// attempts returns the number of attempts the policy allows, treating a nil policy as a single attempt
+func (R *RetryPolicy) attempts() int {
+	if R == nil || R.MaxAttempts < 1 {
+		return 1
+	}
+	return R.MaxAttempts
+}
+
+// backoff returns a jittered delay to wait before the retry following the given zero based attempt
+func (R *RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := R.InitialBackoff
+	for i := 0; i < attempt && (R.MaxBackoff <= 0 || ceiling < R.MaxBackoff); i++ {
+		ceiling *= 2
+	}
+	if R.MaxBackoff > 0 && ceiling > R.MaxBackoff {
+		ceiling = R.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitter.Int63n(int64(ceiling) + 1))
+}
+
+// retryable reports whether a failed attempt is worth repeating
+func retryable(ctx context.Context, httpResp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch httpResp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, in either delay-seconds or HTTP-date form
+func retryAfter(httpResp *http.Response) (time.Duration, bool) {
+	if httpResp == nil {
+		return 0, false
+	}
+	if httpResp.StatusCode != http.StatusTooManyRequests && httpResp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	v := httpResp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,194 @@
+package onetimesecret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+func TestRetryAfterIsCappedAtMaxBackoff(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultRateLimit, Endpoint: "Status", Times: 1, RetryAfter: time.Hour})
+
+	client := srv.Client(t, &onetimesecret.ClientOptions{RetryPolicy: &onetimesecret.RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.StatusWithContext(ctx); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if n := srv.Requests("Status"); n != 2 {
+		t.Errorf("Status was requested %d times, want 2", n)
+	}
+}
+
+func TestRetryAfterWithoutMaxBackoff(t *testing.T) {
+	srv := otstest.NewServer(nil)
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultRateLimit, Endpoint: "Status", Times: 1, RetryAfter: time.Hour})
+
+	client := srv.Client(t, &onetimesecret.ClientOptions{RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: 2}})
+
+	// without a cap the Retry-After delay is honoured, so only the deadline ends the call
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.StatusWithContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRetryNeverRepeatsNonIdempotentCalls(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		call     func(*onetimesecret.Client) error
+	}{
+		{"CreateSecret", func(c *onetimesecret.Client) error {
+			_, err := c.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+			return err
+		}},
+		{"GenerateSecret", func(c *onetimesecret.Client) error {
+			_, err := c.GenerateSecret(&onetimesecret.GenerateSecretRequest{})
+			return err
+		}},
+		{"RetrieveSecret", func(c *onetimesecret.Client) error {
+			_, err := c.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: "0123456789abcdef0123456789abcdef"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			for _, kind := range []otstest.FaultKind{otstest.FaultServerError, otstest.FaultRateLimit, otstest.FaultReset} {
+				srv := newTestServer()
+				srv.InjectFault(otstest.Fault{Kind: kind, Endpoint: tt.endpoint, Times: 1})
+				client := newTestClient(t, srv, &onetimesecret.ClientOptions{RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}})
+
+				err := tt.call(client)
+				var apiErr *onetimesecret.APIError
+				switch {
+				case err == nil:
+					t.Errorf("%s: the call succeeded, so it was retried", kind)
+				case kind != otstest.FaultReset && !errors.As(err, &apiErr):
+					t.Errorf("%s: err = %v, want an *APIError", kind, err)
+				}
+				if n := srv.Requests(tt.endpoint); n != 1 {
+					t.Errorf("%s: %d requests, want 1", kind, n)
+				}
+				srv.Close()
+			}
+		})
+	}
+}
+
+func TestRetryIdempotentCallsSucceed(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		call     func(*onetimesecret.Client, string) error
+	}{
+		{"RetrieveMetadata", func(c *onetimesecret.Client, key string) error {
+			_, err := c.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: key})
+			return err
+		}},
+		{"RetrieveRecentMetadata", func(c *onetimesecret.Client, key string) error {
+			_, err := c.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+			return err
+		}},
+		{"BurnSecret", func(c *onetimesecret.Client, key string) error {
+			_, err := c.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: key})
+			return err
+		}},
+	}
+	faults := []struct {
+		name  string
+		fault otstest.Fault
+	}{
+		{"server errors", otstest.Fault{Kind: otstest.FaultServerError, Times: 2}},
+		{"rate limit", otstest.Fault{Kind: otstest.FaultRateLimit, Times: 2}},
+		// http.Transport resends a GET over a reset keep-alive connection by itself, so only POSTs show the retry
+		{"network error", otstest.Fault{Kind: otstest.FaultReset, Times: 2}},
+	}
+	for _, tt := range tests {
+		for _, f := range faults {
+			if f.fault.Kind == otstest.FaultReset && tt.endpoint == "RetrieveRecentMetadata" {
+				continue
+			}
+			t.Run(tt.endpoint+"/"+f.name, func(t *testing.T) {
+				srv := newTestServer()
+				defer srv.Close()
+				client := newTestClient(t, srv, &onetimesecret.ClientOptions{RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}})
+				created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				fault := f.fault
+				fault.Endpoint = tt.endpoint
+				srv.InjectFault(fault)
+				if err := tt.call(client, created.MetadataKey); err != nil {
+					t.Fatalf("err = %v, want success on the third attempt", err)
+				}
+				if n := srv.Requests(tt.endpoint); n != 3 {
+					t.Errorf("%d requests, want 3", n)
+				}
+			})
+		}
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		want        int
+	}{
+		{"disabled", 0, 1},
+		{"one", 1, 1},
+		{"two", 2, 2},
+		{"four", 4, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer()
+			defer srv.Close()
+			srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "RetrieveMetadata"})
+			client := newTestClient(t, srv, &onetimesecret.ClientOptions{RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: tt.maxAttempts, InitialBackoff: time.Millisecond}})
+
+			_, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: "0123456789abcdef0123456789abcdef"})
+			if !errors.Is(err, onetimesecret.ErrServerError) {
+				t.Errorf("err = %v, want ErrServerError", err)
+			}
+			if n := srv.Requests("RetrieveMetadata"); n != tt.want {
+				t.Errorf("%d requests, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "BurnSecret"})
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{RetryPolicy: &onetimesecret.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.BurnSecretWithContext(ctx, &onetimesecret.BurnSecretRequest{MetadataKey: "0123456789abcdef0123456789abcdef"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("the call returned after %v, want it to stop waiting when the context is cancelled", d)
+	}
+	if n := srv.Requests("BurnSecret"); n > 2 {
+		t.Errorf("%d requests, want at most 2", n)
+	}
+}
@@ -1,26 +1,30 @@
 package onetimesecret
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
-	"strings"
+	"time"
 )
 
-// CreateSecretRequest is a structure that holds data that will be marshalled into https://onetimesecret.com query parameters
+// CreateSecretRequest is a structure that holds data that will be encoded into https://onetimesecret.com form parameters
 //
-//  Query Params
+//  Form Params
 //
 //    secret: the secret value which is encrypted before being stored. There is a maximum length based on your plan that is enforced (1k-10k).
 //    passphrase: a string that the recipient must know to view the secret. This value is also used to encrypt the secret and is bcrypted before being stored so we only have this value in transit.
-//    ttl: the maximum amount of time, in seconds, that the secret should survive (i.e. time-to-live). Once this time expires, the secret will be deleted and not recoverable.
+//    ttl: the maximum amount of time that the secret should survive (i.e. time-to-live), sent in whole seconds. Once this time expires, the secret will be deleted and not recoverable.
 //    recipient: an email address. We will send a friendly email containing the secret link (NOT the secret itself).
+//
+// The form encoding carries any bytes, but the service returns secrets inside JSON responses, so a Secret must be
+// valid UTF-8 to be retrieved intact: invalid bytes come back as U+FFFD. Encode binary data, e.g. with base64, before
+// sharing it.
 type CreateSecretRequest struct {
 	Secret     string
 	Passphrase string
-	TTL        int
+	TTL        time.Duration
 	Recipient  []string
 }
 
@@ -36,34 +40,14 @@ func (C *CreateSecretRequest) Validate() error {
 		return fmt.Errorf("secret can not be left blank")
 	}
 
-	return nil
+	return validateTTL(C.TTL)
 }
 
 // CreateSecretResponse is a structure that will hold data that is unmarshalled from a json response
 //
-//  Attributes
-//
-//    custid: this is you :]
-//    metadata_key: the unique key for the metadata. DO NOT share this.
-//    secret_key: the unique key for the secret you create. This is key that you can share.
-//    ttl: The time-to-live (in seconds) that was specified (i.e. not the time remaining)
-//    metadata_ttl: The remaining time (in seconds) that the metadata has left to live.
-//    secret_ttl: The remaining time (in seconds) that the secret has left to live.
-//    recipient: if a recipient was specified, this is an obfuscated version of the email address.
-//    created: Time the secret was created in unix time (UTC)
-//    updated: ditto, but the time it was last updated.
-//    passphrase_required: If a passphrase was provided when the secret was created, this will be true. Otherwise false, obviously.
+// It is the Metadata of the newly created secret.
 type CreateSecretResponse struct {
-	CustID             string   `json:"custid"`
-	MetadataKey        string   `json:"metadata_key"`
-	SecretKey          string   `json:"secret_key"`
-	TTL                int      `json:"ttl"`
-	MetadataTTL        int      `json:"metadata_ttl"`
-	SecretTTL          int      `json:"secret_ttl"`
-	Recipient          []string `json:"recipient"`
-	CreatedAt          int      `json:"created"`
-	UpdatedAt          int      `json:"updated"`
-	PassphraseRequired bool     `json:"passphrase_required"`
+	Metadata
 }
 
 // Unmarshal will read a json formatted http response body and apply those fields to structure fields
@@ -82,16 +66,16 @@ func (C *CreateSecretResponse) Unmarshal(httpResponseBody io.ReadCloser) error {
 	return json.Unmarshal(b, C)
 }
 
-// GenerateSecretRequest is a structure that holds data that will me marshalled into https://onetimesecret.com query parameters
+// GenerateSecretRequest is a structure that holds data that will be encoded into https://onetimesecret.com form parameters
 //
-//  Query Params
+//  Form Params
 //
 //    passphrase: a string that the recipient must know to view the secret. This value is also used to encrypt the secret and is bcrypted before being stored so we only have this value in transit.
-//    ttl: the maximum amount of time, in seconds, that the secret should survive (i.e. time-to-live). Once this time expires, the secret will be deleted and not recoverable.
+//    ttl: the maximum amount of time that the secret should survive (i.e. time-to-live), sent in whole seconds. Once this time expires, the secret will be deleted and not recoverable.
 //    recipient: an email address. We will send a friendly email containing the secret link (NOT the secret itself).
 type GenerateSecretRequest struct {
 	Passphrase string
-	TTL        int
+	TTL        time.Duration
 	Recipient  []string
 }
 
@@ -103,35 +87,19 @@ type GenerateSecretRequest struct {
 // Returns:
 //     (error): An error if one exists, nil otherwise
 func (G *GenerateSecretRequest) Validate() error {
-	return nil
+	return validateTTL(G.TTL)
 }
 
 // GenerateSecretResponse is a structure that will hold data that is unmarshalled from a json response
 //
 //  Attributes
 //
-//    custid: this is you :]
-//    metadata_key: the unique key for the metadata. DO NOT share this.
-//    secret_key: the unique key for the secret you create. This is key that you can share.
-//    ttl: The time-to-live (in seconds) that was specified (i.e. not the time remaining)
-//    metadata_ttl: The remaining time (in seconds) that the metadata has left to live.
-//    secret_ttl: The remaining time (in seconds) that the secret has left to live.
-//    recipient: if a recipient was specified, this is an obfuscated version of the email address.
-//    created: Time the secret was created in unix time (UTC)
-//    updated: ditto, but the time it was last updated.
-//    passphrase_required: If a passphrase was provided when the secret was created, this will be true. Otherwise false, obviously.
+//    value: the generated secret. It is only returned this one time.
+//
+// The remaining attributes are the Metadata of the newly generated secret.
 type GenerateSecretResponse struct {
-	CustID             string   `json:"custid"`
-	Value              string   `json:"value"`
-	MetadataKey        string   `json:"metadata_key"`
-	SecretKey          string   `json:"secret_key"`
-	TTL                int      `json:"ttl"`
-	MetadataTTL        int      `json:"metadata_ttl"`
-	SecretTTL          int      `json:"secret_ttl"`
-	Recipient          []string `json:"recipient"`
-	CreatedAt          int      `json:"created"`
-	UpdatedAt          int      `json:"updated"`
-	PassphraseRequired bool     `json:"passphrase_required"`
+	Metadata
+	Value string `json:"value"`
 }
 
 // Unmarshal will read a json formatted http response body and apply those fields to structure fields
@@ -150,9 +118,9 @@ func (G *GenerateSecretResponse) Unmarshal(httpResponseBody io.ReadCloser) error
 	return json.Unmarshal(b, G)
 }
 
-// RetrieveSecretRequest is a structure that holds data that will be marshalled into http://onetimesecret.com query parameters
+// RetrieveSecretRequest is a structure that holds data that will be encoded into https://onetimesecret.com form parameters
 //
-//  Query Params
+//  Form Params
 //
 //    SECRET_KEY: the unique key for this secret.
 //    passphrase (if required): the passphrase is required only if the secret was create with one.
@@ -228,31 +196,9 @@ func (R *RetrieveMetadataRequest) Validate() error {
 
 // RetrieveMetadataResponse is a structure that will hold data that is unmarshalled from a json response
 //
-//   Attributes
-//
-//    custid: this is you :]
-//    metadata_key: the unique key for the metadata. DO NOT share this.
-//    secret_key: the unique key for the secret you created. This is key that you can share.
-//    ttl: The time-to-live that was specified (i.e. not the time remaining)
-//    metadata_ttl: The remaining time (in seconds) that the metadata has left to live.
-//    secret_ttl: The remaining time (in seconds) that the secret has left to live.
-//    recipient: if a recipient was specified, this is an obfuscated version of the email address.
-//    created: Time the metadata was created in unix time (UTC)
-//    updated: ditto, but the time it was last updated.
-//    received: Time the secret was received.
-//    passphrase_required: If a passphrase was provided when the secret was created, this will be true. Otherwise false, obviously.
+// It is the Metadata of the secret.
 type RetrieveMetadataResponse struct {
-	CustID             string   `json:"custid"`
-	MetadataKey        string   `json:"metadata_key"`
-	SecretKey          string   `json:"secret_key"`
-	TTL                int      `json:"ttl"`
-	MetadataTTL        int      `json:"metadata_ttl"`
-	SecretTTL          int      `json:"secret_ttl"`
-	Recipient          []string `json:"recipient"`
-	CreatedAt          int      `json:"created"`
-	UpdatedAt          int      `json:"updated"`
-	Received           int      `json:"received"`
-	PassphraseRequired bool     `json:"passphrase_required"`
+	Metadata
 }
 
 // Unmarshal will read a json formatted http response body and apply those fields to structure fields
@@ -296,31 +242,9 @@ func (B *BurnSecretRequest) Validate() error {
 
 // BurnSecretResponse is a structure that will hold data that is unmarshalled from a json response
 //
-//   Attributes
-//
-//    custid: this is you :]
-//    metadata_key: the unique key for the metadata. DO NOT share this.
-//    secret_key: the unique key for the secret you created. This is key that you can share.
-//    ttl: The time-to-live that was specified (i.e. not the time remaining)
-//    metadata_ttl: The remaining time (in seconds) that the metadata has left to live.
-//    secret_ttl: The remaining time (in seconds) that the secret has left to live.
-//    recipient: if a recipient was specified, this is an obfuscated version of the email address.
-//    created: Time the metadata was created in unix time (UTC)
-//    updated: ditto, but the time it was last updated.
-//    received: Time the secret was received.
-//    passphrase_required: If a passphrase was provided when the secret was created, this will be true. Otherwise false, obviously.
+// It is the Metadata of the burned secret.
 type BurnSecretResponse struct {
-	CustID             string   `json:"custid"`
-	MetadataKey        string   `json:"metadata_key"`
-	SecretKey          string   `json:"secret_key"`
-	TTL                int      `json:"ttl"`
-	MetadataTTL        int      `json:"metadata_ttl"`
-	SecretTTL          int      `json:"secret_ttl"`
-	Recipient          []string `json:"recipient"`
-	CreatedAt          int      `json:"created"`
-	UpdatedAt          int      `json:"updated"`
-	Received           int      `json:"received"`
-	PassphraseRequired bool     `json:"passphrase_required"`
+	Metadata
 }
 
 // Unmarshal will read a json formatted http response body and apply those fields to structure fields
@@ -344,32 +268,8 @@ type RetrieveRecentMetadataRequest struct{}
 
 // RetrieveRecentMetadataResponse is a structure that will hold data that is unmarshalled from a json response
 //
-//   List of Attributes
-//
-//    custid: this is you :]
-//    metadata_key: the unique key for the metadata. DO NOT share this.
-//    secret_key: the unique key for the secret you created. This is key that you can share.
-//    ttl: The time-to-live that was specified (i.e. not the time remaining)
-//    metadata_ttl: The remaining time (in seconds) that the metadata has left to live.
-//    secret_ttl: The remaining time (in seconds) that the secret has left to live.
-//    recipient: if a recipient was specified, this is an obfuscated version of the email address.
-//    created: Time the metadata was created in unix time (UTC)
-//    updated: ditto, but the time it was last updated.
-//    received: Time the secret was received.
-//    passphrase_required: If a passphrase was provided when the secret was created, this will be true. Otherwise false, obviously.
-type RetrieveRecentMetadataResponse []struct {
-	CustID             string   `json:"custid"`
-	MetadataKey        string   `json:"metadata_key"`
-	SecretKey          string   `json:"secret_key"`
-	TTL                int      `json:"ttl"`
-	MetadataTTL        int      `json:"metadata_ttl"`
-	SecretTTL          int      `json:"secret_ttl"`
-	Recipient          []string `json:"recipient"`
-	CreatedAt          int      `json:"created"`
-	UpdatedAt          int      `json:"updated"`
-	Received           int      `json:"received"`
-	PassphraseRequired bool     `json:"passphrase_required"`
-}
+// It is the Metadata of each of the recent secrets of the account.
+type RetrieveRecentMetadataResponse []Metadata
 
 // Unmarshal will read a json formatted http response body and apply those fields to structure fields
 //
@@ -396,49 +296,35 @@ func (R *RetrieveRecentMetadataResponse) Unmarshal(httpResponseBody io.ReadClose
 //     (*CreateSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
 //     (error):                 An error if one exists, nil otherwise
 func (C *Client) CreateSecret(request *CreateSecretRequest) (*CreateSecretResponse, error) {
-	var (
-		u        string
-		err      error
-		resp     = new(CreateSecretResponse)
-		httpReq  *http.Request
-		httpResp *http.Response
-	)
+	return C.CreateSecretWithContext(context.Background(), request)
+}
 
+// CreateSecretWithContext will create a secret using the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// It is never retried, as a repeated request could create the secret twice.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//     request (*CreateSecretRequest): A pointer to a CreateSecretRequest struct
+//
+// Returns:
+//     (*CreateSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):                 An error if one exists, nil otherwise
+func (C *Client) CreateSecretWithContext(ctx context.Context, request *CreateSecretRequest) (*CreateSecretResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
 
-	u = fmt.Sprintf("%s/api/v1/share?secret=%s", C.otsURL, request.Secret)
-
-	if request.Passphrase != "" {
-		u = fmt.Sprintf("%s,passphrase=%s", request.Passphrase)
-	}
-	if request.TTL != 0 {
-		u = fmt.Sprintf("%s,ttl=%d", request.TTL)
-	}
-	if request.Recipient != nil {
-		u = fmt.Sprintf("%s,recipient=%s", strings.Join(request.Recipient, ","))
-	}
-
-	httpReq, err = http.NewRequest(http.MethodPost, u, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq.SetBasicAuth(C.creds.Username, C.creds.APIToken)
-
-	httpResp, err = C.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("service returned a non-200 status code: %d", httpResp.StatusCode)
+	op := &operation{
+		name:   "CreateSecret",
+		params: request.Values(),
 	}
 
-	if err := resp.Unmarshal(httpResp.Body); err != nil {
+	resp := new(CreateSecretResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
 		return nil, err
 	}
+	resp.baseURL = C.otsURL
 
 	return resp, nil
 }
@@ -452,53 +338,35 @@ func (C *Client) CreateSecret(request *CreateSecretRequest) (*CreateSecretRespon
 //     (*GenerateSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
 //     (error):                   An error if one exists, nil otherwise
 func (C *Client) GenerateSecret(request *GenerateSecretRequest) (*GenerateSecretResponse, error) {
-	var (
-		params   []string
-		u        string
-		err      error
-		resp     = new(GenerateSecretResponse)
-		httpReq  *http.Request
-		httpResp *http.Response
-	)
+	return C.GenerateSecretWithContext(context.Background(), request)
+}
 
+// GenerateSecretWithContext will generate a secret using the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// It is never retried, as a repeated request could generate the secret twice.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//     request (*GenerateSecretRequest): A pointer to a GenerateSecretRequest struct
+//
+// Returns:
+//     (*GenerateSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):                   An error if one exists, nil otherwise
+func (C *Client) GenerateSecretWithContext(ctx context.Context, request *GenerateSecretRequest) (*GenerateSecretResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
 
-	u = fmt.Sprintf("%s/api/v1/generate", C.otsURL)
-
-	if request.Passphrase != "" || request.TTL != 0 || request.Recipient != nil {
-		if request.Passphrase != "" {
-			params = append(params, fmt.Sprintf("passphrase=%s", request.Passphrase))
-		}
-		if request.TTL != 0 {
-			params = append(params, fmt.Sprintf("ttl=%d", request.TTL))
-		}
-		if request.Recipient != nil {
-			params = append(params, fmt.Sprintf("recipient=%s", strings.Join(request.Recipient, ",")))
-		}
-		u = fmt.Sprintf("%s?%s", u, strings.Join(params, ","))
-	}
-
-	httpReq, err = http.NewRequest(http.MethodPost, u, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq.SetBasicAuth(C.creds.Username, C.creds.APIToken)
-
-	httpResp, err = C.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("service returned a non-200 status code: %d", httpResp.StatusCode)
+	op := &operation{
+		name:   "GenerateSecret",
+		params: request.Values(),
 	}
 
-	if err := resp.Unmarshal(httpResp.Body); err != nil {
+	resp := new(GenerateSecretResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
 		return nil, err
 	}
+	resp.baseURL = C.otsURL
 
 	return resp, nil
 }
@@ -512,41 +380,33 @@ func (C *Client) GenerateSecret(request *GenerateSecretRequest) (*GenerateSecret
 //     (*RetrieveSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
 //     (error):                   An error if one exists, nil otherwise
 func (C *Client) RetrieveSecret(request *RetrieveSecretRequest) (*RetrieveSecretResponse, error) {
-	var (
-		u        string
-		err      error
-		resp     = new(RetrieveSecretResponse)
-		httpReq  *http.Request
-		httpResp *http.Response
-	)
+	return C.RetrieveSecretWithContext(context.Background(), request)
+}
 
+// RetrieveSecretWithContext will retrieve a secret using the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// It is never retried, as a repeated request could consume the secret twice.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//     request (*RetrieveSecretRequest): A pointer to a RetrieveSecretRequest struct
+//
+// Returns:
+//     (*RetrieveSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):                   An error if one exists, nil otherwise
+func (C *Client) RetrieveSecretWithContext(ctx context.Context, request *RetrieveSecretRequest) (*RetrieveSecretResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
 
-	u = fmt.Sprintf("%s/api/v1/secret/%s", C.otsURL, request.SecretKey)
-
-	if request.Passphrase != "" {
-		u = fmt.Sprintf("%s?passphrase=%s", u, request.Passphrase)
+	op := &operation{
+		name:   "RetrieveSecret",
+		key:    request.SecretKey,
+		params: request.Values(),
 	}
 
-	httpReq, err = http.NewRequest(http.MethodPost, u, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq.SetBasicAuth(C.creds.Username, C.creds.APIToken)
-
-	httpResp, err = C.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("service returned a non-200 status code: %d", httpResp.StatusCode)
-	}
-
-	if err := resp.Unmarshal(httpResp.Body); err != nil {
+	resp := new(RetrieveSecretResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
 		return nil, err
 	}
 
@@ -562,39 +422,38 @@ func (C *Client) RetrieveSecret(request *RetrieveSecretRequest) (*RetrieveSecret
 //     (*RetrieveMetadataResponse): A pointer to the response struct that is generated, nil if an error occurred
 //     (error):                     An error if one exists, nil otherwise
 func (C *Client) RetrieveMetadata(request *RetrieveMetadataRequest) (*RetrieveMetadataResponse, error) {
-	var (
-		u        string
-		err      error
-		resp     = new(RetrieveMetadataResponse)
-		httpReq  *http.Request
-		httpResp *http.Response
-	)
+	return C.RetrieveMetadataWithContext(context.Background(), request)
+}
 
+// RetrieveMetadataWithContext will retrieve metadata for a secret using the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// Transient failures are retried according to the Client's RetryPolicy.
+// It needs an account and fails with ErrAuthenticationRequired on an anonymous Client.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//     request (*RetrieveMetadataRequest): A pointer to a RetrieveMetadataRequest struct
+//
+// Returns:
+//     (*RetrieveMetadataResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):                     An error if one exists, nil otherwise
+func (C *Client) RetrieveMetadataWithContext(ctx context.Context, request *RetrieveMetadataRequest) (*RetrieveMetadataResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
 
-	u = fmt.Sprintf("%s/api/v1/private/%s", C.otsURL, request.MetadataKey)
-
-	httpReq, err = http.NewRequest(http.MethodPost, u, nil)
-	if err != nil {
-		return nil, err
+	op := &operation{
+		name:          "RetrieveMetadata",
+		key:           request.MetadataKey,
+		idempotent:    true,
+		authenticated: true,
 	}
 
-	httpReq.SetBasicAuth(C.creds.Username, C.creds.APIToken)
-
-	httpResp, err = C.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("service returned a non-200 status code: %d", httpResp.StatusCode)
-	}
-
-	if err := resp.Unmarshal(httpResp.Body); err != nil {
+	resp := new(RetrieveMetadataResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
 		return nil, err
 	}
+	resp.baseURL = C.otsURL
 
 	return resp, nil
 }
@@ -608,39 +467,38 @@ func (C *Client) RetrieveMetadata(request *RetrieveMetadataRequest) (*RetrieveMe
 //     (*BurnSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
 //     (error):               An error if one exists, nil otherwise
 func (C *Client) BurnSecret(request *BurnSecretRequest) (*BurnSecretResponse, error) {
-	var (
-		u        string
-		err      error
-		resp     = new(BurnSecretResponse)
-		httpReq  *http.Request
-		httpResp *http.Response
-	)
+	return C.BurnSecretWithContext(context.Background(), request)
+}
 
+// BurnSecretWithContext will destroy a secret using the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// Transient failures are retried according to the Client's RetryPolicy.
+// It needs an account and fails with ErrAuthenticationRequired on an anonymous Client.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//     request (*BurnSecretRequest): A pointer to a BurnSecretRequest struct
+//
+// Returns:
+//     (*BurnSecretResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):               An error if one exists, nil otherwise
+func (C *Client) BurnSecretWithContext(ctx context.Context, request *BurnSecretRequest) (*BurnSecretResponse, error) {
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
 
-	u = fmt.Sprintf("%s/api/v1/private/%s/burn", C.otsURL, request.MetadataKey)
-
-	httpReq, err = http.NewRequest(http.MethodPost, u, nil)
-	if err != nil {
-		return nil, err
+	op := &operation{
+		name:          "BurnSecret",
+		key:           request.MetadataKey,
+		idempotent:    true,
+		authenticated: true,
 	}
 
-	httpReq.SetBasicAuth(C.creds.Username, C.creds.APIToken)
-
-	httpResp, err = C.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("service returned a non-200 status code: %d", httpResp.StatusCode)
-	}
-
-	if err := resp.Unmarshal(httpResp.Body); err != nil {
+	resp := new(BurnSecretResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
 		return nil, err
 	}
+	resp.baseURL = C.otsURL
 
 	return resp, nil
 }
@@ -654,34 +512,34 @@ func (C *Client) BurnSecret(request *BurnSecretRequest) (*BurnSecretResponse, er
 //     (*RetrieveRecentMetadataResponse): A pointer to the response struct that is generated, nil if an error occurred
 //     (error):                           An error if one exists, nil otherwise
 func (C *Client) RetrieveRecentMetadata(request *RetrieveRecentMetadataRequest) (*RetrieveRecentMetadataResponse, error) {
-	var (
-		url      string
-		err      error
-		resp     = new(RetrieveRecentMetadataResponse)
-		httpReq  *http.Request
-		httpResp *http.Response
-	)
-
-	url = fmt.Sprintf("%s/api/v1/private/recent", C.otsURL)
+	return C.RetrieveRecentMetadataWithContext(context.Background(), request)
+}
 
-	httpReq, err = http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+// RetrieveRecentMetadataWithContext will retrieve all recent metadata using the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// Transient failures are retried according to the Client's RetryPolicy.
+// It needs an account and fails with ErrAuthenticationRequired on an anonymous Client.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//     request (*RetrieveRecentMetadataRequest): A pointer to a RetrieveRecentMetadataRequest struct
+//
+// Returns:
+//     (*RetrieveRecentMetadataResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):                           An error if one exists, nil otherwise
+func (C *Client) RetrieveRecentMetadataWithContext(ctx context.Context, request *RetrieveRecentMetadataRequest) (*RetrieveRecentMetadataResponse, error) {
+	op := &operation{
+		name:          "RetrieveRecentMetadata",
+		idempotent:    true,
+		authenticated: true,
 	}
 
-	httpReq.SetBasicAuth(C.creds.Username, C.creds.APIToken)
-
-	httpResp, err = C.httpClient.Do(httpReq)
-	if err != nil {
+	resp := new(RetrieveRecentMetadataResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
 		return nil, err
 	}
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("service returned a non-200 status code: %d", httpResp.StatusCode)
-	}
-
-	if err := resp.Unmarshal(httpResp.Body); err != nil {
-		return nil, err
+	for i := range *resp {
+		(*resp)[i].baseURL = C.otsURL
 	}
 
 	return resp, nil
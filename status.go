@@ -0,0 +1,150 @@
+package onetimesecret
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// StatusResponse is a structure that will hold data that is unmarshalled from a json response
+//
+//   Attributes
+//
+//    status: the status of the service, "nominal" when it is healthy.
+//    locale: the default locale of the service.
+type StatusResponse struct {
+	Status string `json:"status"`
+	Locale string `json:"locale"`
+}
+
+// Nominal will report whether the service considers itself healthy
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (bool): true if the status is "nominal", false otherwise
+func (S *StatusResponse) Nominal() bool {
+	return S.Status == "nominal"
+}
+
+// Unmarshal will read a json formatted http response body and apply those fields to structure fields
+//
+// Variables:
+//     httpResponseBody (io.ReadCloser): The interface returned from an http.Client.Do action
+//
+// Returns:
+//     (error): An error if one exists, nil otherwise
+func (S *StatusResponse) Unmarshal(httpResponseBody io.ReadCloser) error {
+	b, err := ioutil.ReadAll(httpResponseBody)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, S)
+}
+
+// AuthcheckResponse is a structure that will hold data that is unmarshalled from a json response
+//
+//   Attributes
+//
+//    custid: this is you :]
+//    role: the role of the account, e.g. "customer".
+type AuthcheckResponse struct {
+	CustID string `json:"custid"`
+	Role   string `json:"role"`
+}
+
+// Unmarshal will read a json formatted http response body and apply those fields to structure fields
+//
+// Variables:
+//     httpResponseBody (io.ReadCloser): The interface returned from an http.Client.Do action
+//
+// Returns:
+//     (error): An error if one exists, nil otherwise
+func (A *AuthcheckResponse) Unmarshal(httpResponseBody io.ReadCloser) error {
+	b, err := ioutil.ReadAll(httpResponseBody)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, A)
+}
+
+// Status will check whether the https://onetimesecret.com service is reachable and healthy
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*StatusResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):           An error if one exists, nil otherwise
+func (C *Client) Status() (*StatusResponse, error) {
+	return C.StatusWithContext(context.Background())
+}
+
+// StatusWithContext will check whether the https://onetimesecret.com service is reachable and healthy, aborting if ctx is cancelled
+//
+// Transient failures are retried according to the Client's RetryPolicy.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//
+// Returns:
+//     (*StatusResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):           An error if one exists, nil otherwise
+func (C *Client) StatusWithContext(ctx context.Context) (*StatusResponse, error) {
+	op := &operation{
+		name:       "Status",
+		idempotent: true,
+	}
+
+	resp := new(StatusResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Authcheck will verify the Client's Credentials against the https://onetimesecret.com service
+//
+// Invalid credentials are reported as an error matching ErrUnauthorized, so that callers can fail fast before doing
+// any real work.
+// It needs an account and fails with ErrAuthenticationRequired on an anonymous Client.
+//
+// Variables:
+//     None
+//
+// Returns:
+//     (*AuthcheckResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):              An error if one exists, nil otherwise
+func (C *Client) Authcheck() (*AuthcheckResponse, error) {
+	return C.AuthcheckWithContext(context.Background())
+}
+
+// AuthcheckWithContext will verify the Client's Credentials against the https://onetimesecret.com service, aborting if ctx is cancelled
+//
+// Transient failures are retried according to the Client's RetryPolicy.
+//
+// Variables:
+//     ctx (context.Context): The context that controls cancellation and deadlines of the request
+//
+// Returns:
+//     (*AuthcheckResponse): A pointer to the response struct that is generated, nil if an error occurred
+//     (error):              An error if one exists, nil otherwise
+func (C *Client) AuthcheckWithContext(ctx context.Context) (*AuthcheckResponse, error) {
+	op := &operation{
+		name:          "Authcheck",
+		idempotent:    true,
+		authenticated: true,
+	}
+
+	resp := new(AuthcheckResponse)
+	if err := C.execute(ctx, op, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
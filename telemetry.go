@@ -0,0 +1,150 @@
+package onetimesecret
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// Tracer starts a Span for every Client call, so that OpenTelemetry or any other tracing library can be plugged in
+// without this package importing it
+//
+// The context returned by Start is used for every request of the call, so a Tracer may store its span in it for
+// propagation by the HTTP transport.
+type Tracer interface {
+	Start(ctx context.Context, operation string) (context.Context, Span)
+}
+
+// Span records a single Client call, from before its first attempt until its response has been decoded
+//
+// Before End is called, the Client sets the attributes named by the SpanAttribute constants. The error passed to End
+// has the same secrets, passphrases and keys redacted from its message as the errors passed to a Logger.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// Span attributes set on every Span
+const (
+	// SpanAttributeStatusCode is the HTTP status code of the final response, 0 if there was none
+	SpanAttributeStatusCode = "http.status_code"
+
+	// SpanAttributeRetryCount is the number of attempts made after the first one
+	SpanAttributeRetryCount = "onetimesecret.retry_count"
+
+	// SpanAttributeErrorType is the ErrorType of a failed call, unset if the call succeeded
+	SpanAttributeErrorType = "error.type"
+)
+
+// Metrics receives counters and histograms for every Client call, so that Prometheus, OpenTelemetry or any other
+// metrics library can be plugged in without this package importing it
+//
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	AddCounter(name string, value int64, labels map[string]string)
+	RecordHistogram(name string, value float64, labels map[string]string)
+}
+
+// Metric names reported to Metrics
+const (
+	// MetricSecretsCreated counts secrets created by CreateSecret and GenerateSecret, labelled by "operation"
+	MetricSecretsCreated = "onetimesecret.secrets.created"
+
+	// MetricSecretsRetrieved counts secrets retrieved by RetrieveSecret
+	MetricSecretsRetrieved = "onetimesecret.secrets.retrieved"
+
+	// MetricSecretsBurned counts secrets burned by BurnSecret
+	MetricSecretsBurned = "onetimesecret.secrets.burned"
+
+	// MetricErrors counts failed calls, labelled by "operation" and "type", see ErrorType
+	MetricErrors = "onetimesecret.errors"
+
+	// MetricCallDuration records the duration of every call in seconds, labelled by "operation"
+	MetricCallDuration = "onetimesecret.call.duration"
+)
+
+// ErrorType will classify an error returned by a Client method, for use as a low-cardinality metric label
+//
+// Variables:
+//     err (error): The error to classify
+//
+// Returns:
+//     (string): One of "not_found", "passphrase_required", "unauthorized", "rate_limited", "server_error",
+//               "api_error", "authentication_required", "canceled", "timeout", "network" or "other", or "" if err
+//               is nil
+func ErrorType(err error) string {
+	var (
+		apiErr *APIError
+		netErr net.Error
+	)
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrPassphraseRequired):
+		return "passphrase_required"
+	case errors.Is(err, ErrSecretNotFound):
+		return "not_found"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrServerError):
+		return "server_error"
+	case errors.As(err, &apiErr):
+		return "api_error"
+	case errors.Is(err, ErrAuthenticationRequired):
+		return "authentication_required"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	return "other"
+}
+
+// instrument reports a finished call to the Client's Tracer span and Metrics
+func (C *Client) instrument(span Span, op *operation, statusCode, attempts int, d time.Duration, err error) {
+	errType := ErrorType(err)
+	retries := attempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+
+	if span != nil {
+		span.SetAttribute(SpanAttributeStatusCode, statusCode)
+		span.SetAttribute(SpanAttributeRetryCount, retries)
+		if errType != "" {
+			span.SetAttribute(SpanAttributeErrorType, errType)
+		}
+		if err != nil {
+			err = &redactedError{op: op, err: err}
+		}
+		span.End(err)
+	}
+
+	if C.metrics == nil {
+		return
+	}
+
+	C.metrics.RecordHistogram(MetricCallDuration, d.Seconds(), map[string]string{"operation": op.name})
+	if err != nil {
+		C.metrics.AddCounter(MetricErrors, 1, map[string]string{"operation": op.name, "type": errType})
+		return
+	}
+
+	switch op.name {
+	case "CreateSecret", "GenerateSecret":
+		C.metrics.AddCounter(MetricSecretsCreated, 1, map[string]string{"operation": op.name})
+	case "RetrieveSecret":
+		C.metrics.AddCounter(MetricSecretsRetrieved, 1, nil)
+	case "BurnSecret":
+		C.metrics.AddCounter(MetricSecretsBurned, 1, nil)
+	}
+}
@@ -0,0 +1,227 @@
+package onetimesecret_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// operations are the six calls of a Client that handle secrets
+var operations = []string{"CreateSecret", "GenerateSecret", "RetrieveSecret", "RetrieveMetadata", "BurnSecret", "RetrieveRecentMetadata"}
+
+// recordedSpan is a Span kept in memory by a recorder
+type recordedSpan struct {
+	operation string
+	attrs     map[string]interface{}
+	err       error
+	ended     bool
+}
+
+func (S *recordedSpan) SetAttribute(key string, value interface{}) {
+	S.attrs[key] = value
+}
+
+func (S *recordedSpan) End(err error) {
+	S.err = err
+	S.ended = true
+}
+
+// recorder is an in-memory Tracer and Metrics
+type recorder struct {
+	mu         sync.Mutex
+	spans      []*recordedSpan
+	counters   map[string]int64
+	histograms map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{counters: make(map[string]int64), histograms: make(map[string]int)}
+}
+
+func (R *recorder) Start(ctx context.Context, operation string) (context.Context, onetimesecret.Span) {
+	R.mu.Lock()
+	defer R.mu.Unlock()
+	span := &recordedSpan{operation: operation, attrs: make(map[string]interface{})}
+	R.spans = append(R.spans, span)
+	return ctx, span
+}
+
+func (R *recorder) AddCounter(name string, value int64, labels map[string]string) {
+	R.mu.Lock()
+	defer R.mu.Unlock()
+	R.counters[metricKey(name, labels)] += value
+}
+
+func (R *recorder) RecordHistogram(name string, value float64, labels map[string]string) {
+	R.mu.Lock()
+	defer R.mu.Unlock()
+	R.histograms[metricKey(name, labels)]++
+}
+
+// span returns the only span of operation, failing the test if there is not exactly one
+func (R *recorder) span(t *testing.T, operation string) *recordedSpan {
+	t.Helper()
+	var found []*recordedSpan
+	for _, s := range R.spans {
+		if s.operation == operation {
+			found = append(found, s)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("%d spans for %s, want 1", len(found), operation)
+	}
+	if !found[0].ended {
+		t.Errorf("the span of %s was not ended", operation)
+	}
+	return found[0]
+}
+
+// metricKey identifies a metric by its name and labels, e.g. `onetimesecret.errors{operation=BurnSecret,type=not_found}`
+func metricKey(name string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// newInstrumentedClient returns a Client of srv that reports to rec
+func newInstrumentedClient(t *testing.T, srv *otstest.Server, rec *recorder, policy *onetimesecret.RetryPolicy) *onetimesecret.Client {
+	t.Helper()
+	return newTestClient(t, srv, &onetimesecret.ClientOptions{Tracer: rec, Metrics: rec, RetryPolicy: policy})
+}
+
+// runOperations performs each of the six operations once, returning their errors by operation
+func runOperations(client *onetimesecret.Client, secretKey, metadataKey string) map[string]error {
+	errs := make(map[string]error)
+	_, errs["CreateSecret"] = client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	_, errs["GenerateSecret"] = client.GenerateSecret(&onetimesecret.GenerateSecretRequest{})
+	_, errs["RetrieveSecret"] = client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: secretKey})
+	_, errs["RetrieveMetadata"] = client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: metadataKey})
+	_, errs["BurnSecret"] = client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: metadataKey})
+	_, errs["RetrieveRecentMetadata"] = client.RetrieveRecentMetadata(&onetimesecret.RetrieveRecentMetadataRequest{})
+	return errs
+}
+
+func TestTelemetrySuccessfulOperations(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	rec := newRecorder()
+	client := newInstrumentedClient(t, srv, rec, nil)
+
+	// the secret of one and the metadata of another, so that both retrieving and burning succeed
+	setup := srv.Client(t, nil)
+	a, err := setup.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := setup.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for op, err := range runOperations(client, a.SecretKey, b.MetadataKey) {
+		if err != nil {
+			t.Fatalf("%s: %v", op, err)
+		}
+	}
+
+	for _, op := range operations {
+		span := rec.span(t, op)
+		if got := span.attrs[onetimesecret.SpanAttributeStatusCode]; got != 200 {
+			t.Errorf("%s: status code = %v, want 200", op, got)
+		}
+		if got := span.attrs[onetimesecret.SpanAttributeRetryCount]; got != 0 {
+			t.Errorf("%s: retry count = %v, want 0", op, got)
+		}
+		if got, ok := span.attrs[onetimesecret.SpanAttributeErrorType]; ok {
+			t.Errorf("%s: error type = %v, want none", op, got)
+		}
+		if span.err != nil {
+			t.Errorf("%s: span ended with %v", op, span.err)
+		}
+		if n := rec.histograms[metricKey(onetimesecret.MetricCallDuration, map[string]string{"operation": op})]; n != 1 {
+			t.Errorf("%s: %d durations recorded, want 1", op, n)
+		}
+	}
+
+	want := map[string]int64{
+		metricKey(onetimesecret.MetricSecretsCreated, map[string]string{"operation": "CreateSecret"}):   1,
+		metricKey(onetimesecret.MetricSecretsCreated, map[string]string{"operation": "GenerateSecret"}): 1,
+		metricKey(onetimesecret.MetricSecretsRetrieved, nil):                                            1,
+		metricKey(onetimesecret.MetricSecretsBurned, nil):                                               1,
+	}
+	if fmt.Sprint(rec.counters) != fmt.Sprint(want) {
+		t.Errorf("counters = %v, want %v", rec.counters, want)
+	}
+}
+
+func TestTelemetryFailedOperations(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError})
+	rec := newRecorder()
+	client := newInstrumentedClient(t, srv, rec, nil)
+
+	const key = "0123456789abcdef0123456789abcdef"
+	for op, err := range runOperations(client, key, key) {
+		if err == nil {
+			t.Fatalf("%s succeeded against a failing server", op)
+		}
+	}
+
+	want := make(map[string]int64)
+	for _, op := range operations {
+		span := rec.span(t, op)
+		if got := span.attrs[onetimesecret.SpanAttributeStatusCode]; got != 500 {
+			t.Errorf("%s: status code = %v, want 500", op, got)
+		}
+		if got := span.attrs[onetimesecret.SpanAttributeErrorType]; got != "server_error" {
+			t.Errorf("%s: error type = %v, want server_error", op, got)
+		}
+		if span.err == nil {
+			t.Errorf("%s: span ended without an error", op)
+		} else if strings.Contains(span.err.Error(), key) {
+			t.Errorf("%s: the span error reveals the key: %v", op, span.err)
+		}
+		want[metricKey(onetimesecret.MetricErrors, map[string]string{"operation": op, "type": "server_error"})] = 1
+	}
+	if fmt.Sprint(rec.counters) != fmt.Sprint(want) {
+		t.Errorf("counters = %v, want %v", rec.counters, want)
+	}
+}
+
+func TestTelemetryRetryCount(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "RetrieveMetadata", Times: 2})
+	rec := newRecorder()
+	client := newInstrumentedClient(t, srv, rec, &onetimesecret.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RetrieveMetadata(&onetimesecret.RetrieveMetadataRequest{MetadataKey: created.MetadataKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	span := rec.span(t, "RetrieveMetadata")
+	if got := span.attrs[onetimesecret.SpanAttributeRetryCount]; got != 2 {
+		t.Errorf("retry count = %v, want 2", got)
+	}
+	if got := span.attrs[onetimesecret.SpanAttributeStatusCode]; got != 200 {
+		t.Errorf("status code = %v, want 200", got)
+	}
+	if got := rec.span(t, "CreateSecret").attrs[onetimesecret.SpanAttributeRetryCount]; got != 0 {
+		t.Errorf("CreateSecret retry count = %v, want 0", got)
+	}
+}
@@ -0,0 +1,101 @@
+package onetimesecret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// validateTTL checks that a requested time-to-live can be sent in whole seconds
+func validateTTL(ttl time.Duration) error {
+	if ttl < 0 || ttl > 0 && ttl < time.Second {
+		return fmt.Errorf("TTL must be 0 or at least 1s, got %s", ttl)
+	}
+	return nil
+}
+
+// jsonNumber extracts the number from a JSON number or a string holding one, reporting false for null or ""
+func jsonNumber(b []byte) (float64, bool, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return 0, false, nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return 0, false, err
+		}
+		if s == "" {
+			return 0, false, nil
+		}
+		b = []byte(s)
+	}
+
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return f, true, nil
+}
+
+// unixTime is a timestamp sent by the service as unix seconds, either as a number or a string, or as an RFC 3339 string
+//
+// null, "" and 0 all decode to the zero time.Time.
+type unixTime time.Time
+
+// UnmarshalJSON implements json.Unmarshaler
+func (U *unixTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			*U = unixTime(t)
+			return nil
+		}
+	}
+
+	f, ok, err := jsonNumber(b)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s", b)
+	}
+	if !ok || f == 0 {
+		*U = unixTime(time.Time{})
+		return nil
+	}
+
+	sec := int64(f)
+	*U = unixTime(time.Unix(sec, int64((f-float64(sec))*float64(time.Second))).UTC())
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the timestamp as unix seconds and the zero time.Time as null
+func (U unixTime) MarshalJSON() ([]byte, error) {
+	t := time.Time(U)
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
+
+// seconds is a duration sent by the service as seconds, either as a number or a string
+//
+// null and "" decode to 0.
+type seconds time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (S *seconds) UnmarshalJSON(b []byte) error {
+	f, _, err := jsonNumber(b)
+	if err != nil {
+		return fmt.Errorf("invalid number of seconds %s", b)
+	}
+	*S = seconds(f * float64(time.Second))
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the duration as whole seconds
+func (S seconds) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(time.Duration(S)/time.Second), 10)), nil
+}
+
@@ -0,0 +1,111 @@
+package onetimesecret_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+)
+
+func TestTimestamps(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    time.Time
+		wantErr bool
+	}{
+		{json: `1700000000`, want: time.Unix(1700000000, 0)},
+		{json: `"1700000000"`, want: time.Unix(1700000000, 0)},
+		{json: `1700000000.5`, want: time.Unix(1700000000, 500000000)},
+		{json: `" 1700000000 "`, wantErr: true},
+		{json: `"2023-11-14T22:13:20Z"`, want: time.Unix(1700000000, 0)},
+		{json: `"2023-11-14T23:13:20+01:00"`, want: time.Unix(1700000000, 0)},
+		{json: `""`, want: time.Time{}},
+		{json: `null`, want: time.Time{}},
+		{json: `0`, want: time.Time{}},
+		{json: `"0"`, want: time.Time{}},
+		{json: `"yesterday"`, wantErr: true},
+		{json: `true`, wantErr: true},
+		{json: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var m onetimesecret.Metadata
+			err := json.Unmarshal([]byte(`{"created": `+tt.json+`}`), &m)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid timestamp") {
+					t.Errorf("CreatedAt = %v, %v, want an invalid timestamp error", m.CreatedAt, err)
+				}
+				return
+			}
+			if err != nil || !m.CreatedAt.Equal(tt.want) || m.CreatedAt.IsZero() != tt.want.IsZero() {
+				t.Errorf("CreatedAt = %v, %v, want %v", m.CreatedAt, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{json: `3600`, want: time.Hour},
+		{json: `"3600"`, want: time.Hour},
+		{json: `1.5`, want: 1500 * time.Millisecond},
+		{json: `""`, want: 0},
+		{json: `null`, want: 0},
+		{json: `0`, want: 0},
+		{json: `-1`, want: -time.Second},
+		{json: `"1h"`, wantErr: true},
+		{json: `false`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var m onetimesecret.Metadata
+			err := json.Unmarshal([]byte(`{"ttl": `+tt.json+`}`), &m)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid number of seconds") {
+					t.Errorf("TTL = %v, %v, want an invalid number of seconds error", m.TTL, err)
+				}
+				return
+			}
+			if err != nil || m.TTL != tt.want {
+				t.Errorf("TTL = %v, %v, want %v", m.TTL, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimesRoundTrip(t *testing.T) {
+	in := onetimesecret.Metadata{
+		MetadataKey: "abc",
+		TTL:         time.Hour,
+		SecretTTL:   90 * time.Second,
+		CreatedAt:   time.Unix(1700000000, 0).UTC(),
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{"ttl": 3600.0, "secret_ttl": 90.0, "created": 1700000000.0, "received": nil} {
+		if got := fields[key]; got != want {
+			t.Errorf("%s = %v, want %v in %s", key, got, want, b)
+		}
+	}
+
+	var out onetimesecret.Metadata
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.TTL != in.TTL || out.SecretTTL != in.SecretTTL || !out.CreatedAt.Equal(in.CreatedAt) || !out.Received.IsZero() {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
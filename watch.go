@@ -0,0 +1,209 @@
+package onetimesecret
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// EventKind is what a WatchEvent reports about a watched secret
+type EventKind string
+
+const (
+	// EventReceived is sent when the recipient has revealed the secret
+	EventReceived EventKind = "received"
+
+	// EventBurned is sent when the secret was destroyed before it was received
+	EventBurned EventKind = "burned"
+
+	// EventExpired is sent when the TTL of the secret ran out before it was received
+	EventExpired EventKind = "expired"
+
+	// EventError is sent when polling the metadata of the secret failed
+	EventError EventKind = "error"
+)
+
+const (
+	// DefaultWatchInterval is the delay between the first polls of a Watcher
+	DefaultWatchInterval = 5 * time.Second
+
+	// DefaultWatchMaxInterval is the longest delay between two polls of a Watcher
+	DefaultWatchMaxInterval = time.Minute
+)
+
+// WatchEvent is sent by a Watcher when a watched secret reaches a final state or polling it fails
+//
+//  Attributes
+//
+//    Kind: what happened to the secret.
+//    MetadataKey: the metadata key of the secret, as given to Watch.
+//    Metadata: the last metadata retrieved for the secret, nil if it could not be retrieved yet.
+//    Err: why polling failed, for EventError only.
+//    Final: whether the Watcher stopped watching the secret, so that no further event will be sent for it. It is
+//           always true for EventReceived, EventBurned and EventExpired. EventError is final when retrying can not
+//           help, i.e. when the metadata does not exist or the credentials are rejected.
+type WatchEvent struct {
+	Kind        EventKind
+	MetadataKey string
+	Metadata    *Metadata
+	Err         error
+	Final       bool
+}
+
+// WatchOptions adjust how often a Watcher polls
+//
+//  Attributes
+//
+//    Interval: the delay before the second poll of a secret, DefaultWatchInterval if zero.
+//    MaxInterval: the cap on the delay between polls, DefaultWatchMaxInterval if zero.
+//
+// The delay doubles after every poll that finds the secret unchanged or fails, up to MaxInterval, and falls back to
+// Interval when the state of the secret changes, e.g. when its share link is opened. A poll is also scheduled right
+// after the secret expires so that the expiry is reported promptly.
+type WatchOptions struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+// Watcher polls the metadata of secrets until they are received, burned or expired
+type Watcher struct {
+	client      *Client
+	interval    time.Duration
+	maxInterval time.Duration
+}
+
+// NewWatcher will generate a new Watcher
+//
+// Variables:
+//     client (*Client):      The Client to poll the metadata with. Watching many secrets is best done with a Client
+//                            that has a RateLimiter.
+//     opts (*WatchOptions):  A pointer to the WatchOptions, nil for the defaults
+//
+// Returns:
+//     (*Watcher): A pointer to a new instance of Watcher
+func NewWatcher(client *Client, opts *WatchOptions) *Watcher {
+	W := &Watcher{
+		client:      client,
+		interval:    DefaultWatchInterval,
+		maxInterval: DefaultWatchMaxInterval,
+	}
+	if opts != nil && opts.Interval > 0 {
+		W.interval = opts.Interval
+	}
+	if opts != nil && opts.MaxInterval > 0 {
+		W.maxInterval = opts.MaxInterval
+	}
+	if W.maxInterval < W.interval {
+		W.maxInterval = W.interval
+	}
+	return W
+}
+
+// Watch will poll the metadata of every given secret until it reaches a final state
+//
+// Every secret is polled concurrently, right away and then with backoff. The returned channel receives an event when
+// a secret reaches a final state and whenever polling it fails, and is closed once every secret has reached a final
+// state or ctx is done. The channel must be drained until it is closed or ctx must be cancelled, otherwise the
+// Watcher blocks.
+//
+// Variables:
+//     ctx (context.Context):   The context that stops the Watcher when it is done
+//     metadataKeys (...string): The metadata keys of the secrets to watch
+//
+// Returns:
+//     (<-chan WatchEvent): The channel the events are sent on
+func (W *Watcher) Watch(ctx context.Context, metadataKeys ...string) <-chan WatchEvent {
+	events := make(chan WatchEvent, len(metadataKeys))
+
+	var wg sync.WaitGroup
+	wg.Add(len(metadataKeys))
+	for _, key := range metadataKeys {
+		go func(key string) {
+			defer wg.Done()
+			W.watch(ctx, key, events)
+		}(key)
+	}
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	return events
+}
+
+// watch polls a single secret until it reaches a final state, a final error occurs or ctx is done
+func (W *Watcher) watch(ctx context.Context, key string, events chan<- WatchEvent) {
+	var (
+		last     *Metadata
+		interval = W.interval
+		delay    time.Duration
+	)
+
+	for {
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+
+		resp, err := W.client.RetrieveMetadataWithContext(ctx, &RetrieveMetadataRequest{MetadataKey: key})
+		if ctx.Err() != nil {
+			return
+		}
+
+		var event *WatchEvent
+		switch {
+		case err != nil:
+			event = &WatchEvent{Kind: EventError, MetadataKey: key, Metadata: last, Err: err, Final: finalWatchError(err)}
+		case resp.State.Final():
+			m := resp.Metadata
+			event = &WatchEvent{Kind: stateEvents[m.State], MetadataKey: key, Metadata: &m, Final: true}
+		}
+		if event != nil {
+			select {
+			case events <- *event:
+			case <-ctx.Done():
+				return
+			}
+			if event.Final {
+				return
+			}
+		}
+
+		if err == nil && last != nil && resp.State != last.State {
+			interval = W.interval
+		}
+		delay = interval
+		if interval *= 2; interval > W.maxInterval {
+			interval = W.maxInterval
+		}
+
+		if err == nil {
+			m := resp.Metadata
+			last = &m
+		}
+		if last != nil {
+			// poll right after the secret expires rather than up to MaxInterval later
+			if untilExpiry := time.Until(last.ExpiresAt()) + time.Second; untilExpiry > 0 && untilExpiry < delay {
+				delay = untilExpiry
+			}
+		}
+	}
+}
+
+// stateEvents maps the final states onto the events that report them
+var stateEvents = map[State]EventKind{
+	StateReceived: EventReceived,
+	StateBurned:   EventBurned,
+	StateExpired:  EventExpired,
+}
+
+// finalWatchError reports whether polling a secret that failed with err is not worth repeating
+func finalWatchError(err error) bool {
+	return errors.Is(err, ErrSecretNotFound) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrAuthenticationRequired)
+}
@@ -0,0 +1,264 @@
+package onetimesecret_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	onetimesecret "github.com/j4ng5y/onetimesecret-go"
+	"github.com/j4ng5y/onetimesecret-go/otstest"
+)
+
+// fastWatch polls every few milliseconds so that the tests of the Watcher run quickly
+var fastWatch = &onetimesecret.WatchOptions{Interval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+
+// collect reads the events of a Watcher until its channel is closed, failing the test if that takes too long
+func collect(t *testing.T, events <-chan onetimesecret.WatchEvent) []onetimesecret.WatchEvent {
+	t.Helper()
+	var got []onetimesecret.WatchEvent
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("the channel was not closed, events so far: %+v", got)
+		}
+	}
+}
+
+// pollRecorder is an http.RoundTripper that records when the metadata of a secret is polled
+type pollRecorder struct {
+	mu        sync.Mutex
+	transport http.RoundTripper
+	times     []time.Time
+}
+
+func (P *pollRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	P.mu.Lock()
+	P.times = append(P.times, time.Now())
+	P.mu.Unlock()
+	return P.transport.RoundTrip(req)
+}
+
+// polls returns the times of the polls so far
+func (P *pollRecorder) polls() []time.Time {
+	P.mu.Lock()
+	defer P.mu.Unlock()
+	return append([]time.Time(nil), P.times...)
+}
+
+func TestWatcherFinalStates(t *testing.T) {
+	clock := otstest.NewClock(time.Unix(1700000000, 0))
+	srv := otstest.NewServer(&otstest.ServerOptions{Now: clock.Now, Accounts: map[string]string{testUsername: testAPIToken}})
+	defer srv.Close()
+	client := newTestClient(t, srv, nil)
+
+	create := func() *onetimesecret.CreateSecretResponse {
+		resp, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret", TTL: time.Hour})
+		if err != nil {
+			t.Fatalf("CreateSecret: %v", err)
+		}
+		return resp
+	}
+	received, burned, expired := create(), create(), create()
+
+	events := onetimesecret.NewWatcher(client, fastWatch).Watch(context.Background(), received.MetadataKey, burned.MetadataKey, expired.MetadataKey)
+
+	// let the secrets be polled unchanged before they change
+	for srv.Requests("RetrieveMetadata") < 6 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Minute)
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: received.SecretKey}); err != nil {
+		t.Fatalf("RetrieveSecret: %v", err)
+	}
+	if _, err := client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: burned.MetadataKey}); err != nil {
+		t.Fatalf("BurnSecret: %v", err)
+	}
+	clock.Advance(time.Hour)
+
+	want := map[string]onetimesecret.EventKind{
+		received.MetadataKey: onetimesecret.EventReceived,
+		burned.MetadataKey:   onetimesecret.EventBurned,
+		expired.MetadataKey:  onetimesecret.EventExpired,
+	}
+	got := collect(t, events)
+	if len(got) != len(want) {
+		t.Fatalf("%d events, want %d: %+v", len(got), len(want), got)
+	}
+	for _, e := range got {
+		if e.Kind != want[e.MetadataKey] || !e.Final || e.Err != nil {
+			t.Errorf("event = %+v, want a final %s event", e, want[e.MetadataKey])
+			continue
+		}
+		if e.Metadata == nil || e.Metadata.MetadataKey != e.MetadataKey || string(e.Metadata.State) != string(e.Kind) {
+			t.Errorf("Metadata = %+v, want the %s metadata of %s", e.Metadata, e.Kind, e.MetadataKey)
+		}
+	}
+}
+
+func TestWatcherErrors(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil)
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	if _, err := client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey}); err != nil {
+		t.Fatalf("RetrieveSecret: %v", err)
+	}
+	srv.InjectFault(otstest.Fault{Kind: otstest.FaultServerError, Endpoint: "RetrieveMetadata", Times: 2})
+
+	got := collect(t, onetimesecret.NewWatcher(client, fastWatch).Watch(context.Background(), created.MetadataKey))
+	if len(got) != 3 {
+		t.Fatalf("%d events, want 2 errors and the received secret: %+v", len(got), got)
+	}
+	for _, e := range got[:2] {
+		if e.Kind != onetimesecret.EventError || e.Final || !errors.Is(e.Err, onetimesecret.ErrServerError) || e.Metadata != nil {
+			t.Errorf("event = %+v, want a non-final error of a server error", e)
+		}
+	}
+	if e := got[2]; e.Kind != onetimesecret.EventReceived || !e.Final {
+		t.Errorf("event = %+v, want the final received event after the errors", e)
+	}
+	if n := srv.Requests("RetrieveMetadata"); n != 3 {
+		t.Errorf("%d polls, want 3", n)
+	}
+
+	t.Run("final", func(t *testing.T) {
+		const unknown = "0123456789abcdef0123456789abcdef"
+		got := collect(t, onetimesecret.NewWatcher(client, fastWatch).Watch(context.Background(), unknown))
+		if len(got) != 1 || got[0].Kind != onetimesecret.EventError || !got[0].Final || !errors.Is(got[0].Err, onetimesecret.ErrSecretNotFound) {
+			t.Errorf("events = %+v, want a single final error of an unknown secret", got)
+		}
+	})
+}
+
+func TestWatcherBackoff(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	rec := &pollRecorder{transport: srv.HTTPClient().Transport}
+	client := newTestClient(t, srv, &onetimesecret.ClientOptions{HTTPClient: &http.Client{Transport: rec}})
+
+	created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	rec.times = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := onetimesecret.NewWatcher(client, &onetimesecret.WatchOptions{Interval: 20 * time.Millisecond, MaxInterval: 80 * time.Millisecond}).Watch(ctx, created.MetadataKey)
+	for len(rec.polls()) < 7 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if got := collect(t, events); len(got) != 0 {
+		t.Errorf("events = %+v, want none for a secret that did not change", got)
+	}
+
+	// the delay doubles from Interval and is capped at MaxInterval. Timers never fire early, so the gaps are at
+	// least the delays, and far below the uncapped delays of 160ms and more.
+	polls := rec.polls()
+	want := []time.Duration{20, 40, 80, 80, 80, 80}
+	for i, w := range want {
+		gap := polls[i+1].Sub(polls[i])
+		if w *= time.Millisecond; gap < w || gap >= 2*w+100*time.Millisecond {
+			t.Errorf("gap before poll %d = %v, want about %v", i+2, gap, w)
+		}
+	}
+}
+
+func TestWatcherCancel(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil)
+
+	var keys []string
+	for i := 0; i < 5; i++ {
+		created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+		if err != nil {
+			t.Fatalf("CreateSecret: %v", err)
+		}
+		keys = append(keys, created.MetadataKey)
+	}
+	srv.HTTPClient().CloseIdleConnections()
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := onetimesecret.NewWatcher(client, fastWatch).Watch(ctx, keys...)
+	for srv.Requests("RetrieveMetadata") < 3*len(keys) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if got := collect(t, events); len(got) != 0 {
+		t.Errorf("events = %+v, want none", got)
+	}
+
+	// the goroutines of the Watcher are gone once the channel is closed, those of the connections once they are
+	// closed
+	srv.HTTPClient().CloseIdleConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines after cancelling, want at most the %d before watching", n, before)
+	}
+}
+
+func TestWatcherManyKeys(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil)
+
+	const n = 50
+	want := map[string]onetimesecret.EventKind{}
+	for i := 0; i < n; i++ {
+		created, err := client.CreateSecret(&onetimesecret.CreateSecretRequest{Secret: "s3cret"})
+		if err != nil {
+			t.Fatalf("CreateSecret: %v", err)
+		}
+		if i%2 == 0 {
+			_, err = client.RetrieveSecret(&onetimesecret.RetrieveSecretRequest{SecretKey: created.SecretKey})
+			want[created.MetadataKey] = onetimesecret.EventReceived
+		} else {
+			_, err = client.BurnSecret(&onetimesecret.BurnSecretRequest{MetadataKey: created.MetadataKey})
+			want[created.MetadataKey] = onetimesecret.EventBurned
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	const unknown = "0123456789abcdef0123456789abcdef"
+	want[unknown] = onetimesecret.EventError
+
+	keys := make([]string, 0, len(want))
+	for key := range want {
+		keys = append(keys, key)
+	}
+	got := collect(t, onetimesecret.NewWatcher(client, fastWatch).Watch(context.Background(), keys...))
+
+	seen := map[string]bool{}
+	for _, e := range got {
+		if seen[e.MetadataKey] {
+			t.Errorf("second event for %s: %+v", e.MetadataKey, e)
+		}
+		seen[e.MetadataKey] = true
+		if e.Kind != want[e.MetadataKey] || !e.Final {
+			t.Errorf("event = %+v, want a final %s event", e, want[e.MetadataKey])
+		}
+	}
+	if len(seen) != len(want) {
+		t.Errorf("events for %d secrets, want %d", len(seen), len(want))
+	}
+}